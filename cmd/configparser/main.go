@@ -0,0 +1,86 @@
+// Command configparser provides tools to work with configuration files.
+//
+// Usage:
+//
+//	configparser diff [-effective] FROM TO
+//
+// The diff subcommand prints the differences between two configuration
+// files, exiting with status 1 if they differ.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bigkevmcd/go-configparser"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, "usage: configparser <command> [arguments]")
+		return 2
+	}
+
+	switch args[0] {
+	case "diff":
+		return diff(args[1:], stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "configparser: unknown command %q\n", args[0])
+		return 2
+	}
+}
+
+func diff(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	effective := fs.Bool("effective", false, "compare values with defaults merged and interpolations expanded")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: configparser diff [-effective] FROM TO")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	from, to := fs.Arg(0), fs.Arg(1)
+	a, err := configparser.Parse(from)
+	if err != nil {
+		fmt.Fprintf(stderr, "configparser: %s\n", err)
+		return 2
+	}
+	b, err := configparser.Parse(to)
+	if err != nil {
+		fmt.Fprintf(stderr, "configparser: %s\n", err)
+		return 2
+	}
+
+	var d *configparser.DiffResult
+	if *effective {
+		d, err = configparser.Diff(a, b, configparser.DiffNames(from, to), configparser.EffectiveValues)
+	} else {
+		d, err = configparser.Diff(a, b, configparser.DiffNames(from, to))
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "configparser: %s\n", err)
+		return 2
+	}
+	if d.Empty() {
+		return 0
+	}
+	if err := d.WriteUnified(stdout); err != nil {
+		fmt.Fprintf(stderr, "configparser: %s\n", err)
+		return 2
+	}
+
+	return 1
+}
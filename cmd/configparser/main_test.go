@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gc "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { gc.TestingT(t) }
+
+type MainSuite struct {
+	dir string
+}
+
+var _ = gc.Suite(&MainSuite{})
+
+func (s *MainSuite) SetUpTest(c *gc.C) {
+	s.dir = c.MkDir()
+	for name, data := range map[string]string{
+		"a.cfg":      "[DEFAULT]\nbase = /srv\n\n[app]\ndir = %(base)s/app\nport = 80\n",
+		"b.cfg":      "[DEFAULT]\nbase = /opt\n\n[app]\ndir = %(base)s/app\nport = 80\n",
+		"broken.cfg": "key = value\n",
+	} {
+		err := os.WriteFile(filepath.Join(s.dir, name), []byte(data), 0o644)
+		c.Assert(err, gc.IsNil)
+	}
+}
+
+// run(args, stdout, stderr) should dispatch the subcommands and report
+// the results with the exit codes.
+func (s *MainSuite) TestRun(c *gc.C) {
+	a, b := filepath.Join(s.dir, "a.cfg"), filepath.Join(s.dir, "b.cfg")
+
+	tests := []struct {
+		description string
+		args        []string
+		code        int
+		stdout      string
+		stderr      string
+	}{
+		{
+			description: "no command",
+			args:        nil,
+			code:        2,
+			stderr:      "usage: configparser <command> [arguments]\n",
+		},
+		{
+			description: "unknown command",
+			args:        []string{"merge"},
+			code:        2,
+			stderr:      "configparser: unknown command \"merge\"\n",
+		},
+		{
+			description: "equal files",
+			args:        []string{"diff", a, a},
+			code:        0,
+		},
+		{
+			description: "different files",
+			args:        []string{"diff", a, b},
+			code:        1,
+			stdout:      "--- " + a + "\n+++ " + b + "\n [DEFAULT]\n-base = /srv\n+base = /opt\n",
+		},
+		{
+			description: "effective values",
+			args:        []string{"diff", "-effective", a, b},
+			code:        1,
+			stdout: "--- " + a + "\n+++ " + b + "\n [DEFAULT]\n-base = /srv\n+base = /opt\n" +
+				" [app]\n-base = /srv\n+base = /opt\n-dir = /srv/app\n+dir = /opt/app\n",
+		},
+		{
+			description: "missing argument",
+			args:        []string{"diff", a},
+			code:        2,
+			stderr:      "usage: configparser diff [-effective] FROM TO\n",
+		},
+		{
+			description: "unknown flag",
+			args:        []string{"diff", "-unknown", a, b},
+			code:        2,
+			stderr:      "flag provided but not defined: -unknown\n",
+		},
+		{
+			description: "missing file",
+			args:        []string{"diff", a, filepath.Join(s.dir, "missing.cfg")},
+			code:        2,
+			stderr:      "configparser: open " + filepath.Join(s.dir, "missing.cfg") + ": no such file or directory\n",
+		},
+		{
+			description: "invalid file",
+			args:        []string{"diff", filepath.Join(s.dir, "broken.cfg"), b},
+			code:        2,
+			stderr:      "configparser: missing section header: 1 key = value\n",
+		},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr strings.Builder
+		code := run(tt.args, &stdout, &stderr)
+
+		c.Check(code, gc.Equals, tt.code, gc.Commentf(tt.description))
+		c.Check(stdout.String(), gc.Equals, tt.stdout, gc.Commentf(tt.description))
+		// Usage output continues with the flag defaults.
+		c.Check(strings.HasPrefix(stderr.String(), tt.stderr), gc.Equals, true,
+			gc.Commentf("%s: %q", tt.description, stderr.String()))
+		if tt.stderr == "" {
+			c.Check(stderr.String(), gc.Equals, "", gc.Commentf(tt.description))
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package configparser
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// escapeChar escapes the inline comment prefixes within values.
+const escapeChar = `\`
+
+// prefixOf returns the first prefix str starts with.
+func (pr Prefixes) prefixOf(str string) (string, bool) {
+	for _, p := range pr {
+		if p != "" && strings.HasPrefix(str, p) {
+			return p, true
+		}
+	}
+
+	return "", false
+}
+
+// splitComment splits str into the value and the inline comment, which
+// starts with one of the prefixes at the beginning of str or after
+// whitespace, outside of the quoted parts. Escaped prefixes, e.g. `\#`, are
+// kept in the value without the escape character.
+//
+// Returned comment includes the prefix, empty if there is none.
+func (pr Prefixes) splitComment(str string) (string, string) {
+	if len(pr) == 0 {
+		return str, ""
+	}
+
+	var (
+		value strings.Builder
+		// quote is the quote character of the current quoted part.
+		quote     rune
+		prevSpace = true
+	)
+	for i := 0; i < len(str); {
+		rest := str[i:]
+		if strings.HasPrefix(rest, escapeChar) {
+			if prefix, ok := pr.prefixOf(rest[len(escapeChar):]); ok {
+				value.WriteString(prefix)
+				i += len(escapeChar) + len(prefix)
+				prevSpace = false
+				continue
+			}
+		}
+
+		r, size := utf8.DecodeRuneInString(rest)
+		if quote != 0 {
+			if r == quote {
+				quote = 0
+			}
+		} else if prevSpace {
+			if _, ok := pr.prefixOf(rest); ok {
+				return strings.TrimRightFunc(value.String(), unicode.IsSpace), rest
+			}
+			// Quotes are recognized at the beginning of words only,
+			// so apostrophes within words are not.
+			if r == '"' || r == '\'' {
+				quote = r
+			}
+		}
+
+		value.WriteString(rest[:size])
+		prevSpace = unicode.IsSpace(r)
+		i += size
+	}
+
+	return value.String(), ""
+}
+
+// escape escapes the prefixes within str, which would start an inline
+// comment or would be unescaped, see splitComment.
+func (pr Prefixes) escape(str string) string {
+	if len(pr) == 0 {
+		return str
+	}
+
+	var (
+		value     strings.Builder
+		quote     rune
+		prevSpace = true
+	)
+	for i := 0; i < len(str); {
+		rest := str[i:]
+		if strings.HasPrefix(rest, escapeChar) {
+			if _, ok := pr.prefixOf(rest[len(escapeChar):]); ok {
+				value.WriteString(escapeChar)
+			}
+		}
+
+		r, size := utf8.DecodeRuneInString(rest)
+		if quote != 0 {
+			if r == quote {
+				quote = 0
+			}
+		} else if prevSpace {
+			if prefix, ok := pr.prefixOf(rest); ok {
+				value.WriteString(escapeChar + prefix)
+				i += len(prefix)
+				prevSpace = false
+				continue
+			}
+			if r == '"' || r == '\'' {
+				quote = r
+			}
+		}
+
+		value.WriteString(rest[:size])
+		prevSpace = unicode.IsSpace(r)
+		i += size
+	}
+
+	return value.String()
+}
+
+// Comment returns the inline comment of the named option, including
+// the prefix, which is written back after the first line of the value.
+// Returns the comment of the first value of the repeated keys, see
+// DuplicateCollect. Returns empty string if the option has no comment.
+//
+// Returns an error if the section does not exist.
+// Returns an error if the option does not exist in the section.
+func (p *ConfigParser) Comment(section, option string) (string, error) {
+	s, err := p.section(section)
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.Get(option); err != nil {
+		return "", err
+	}
+
+	return s.comment(option, 0), nil
+}
+
+// SetComment sets the inline comment of the named option, which should start
+// with one of the inline comment prefixes to be parsed back. Sets the comment
+// of the first value of the repeated keys. Empty comment removes it.
+//
+// Returns an error if the section does not exist.
+// Returns an error if the option does not exist in the section.
+func (p *ConfigParser) SetComment(section, option, comment string) error {
+	s, err := p.section(section)
+	if err != nil {
+		return err
+	}
+	if _, err := s.Get(option); err != nil {
+		return err
+	}
+	s.setComment(option, 0, comment)
+
+	return nil
+}
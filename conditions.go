@@ -0,0 +1,124 @@
+package configparser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// conditionalIncludeOption lists the files of a conditional include section.
+const conditionalIncludeOption = "path"
+
+var conditionalInclude = regexp.MustCompile(`^includeIf\s+"(.*)"$`)
+
+// includeCondition checks if the section header is a conditional include
+// and returns its condition.
+func (p *ConfigParser) includeCondition(section string) (string, bool) {
+	if p.opt.conditions == nil {
+		return "", false
+	}
+	match := conditionalInclude.FindStringSubmatch(strings.TrimSpace(section))
+	if match == nil {
+		return "", false
+	}
+
+	return match[1], true
+}
+
+// ConditionEvaluator decides whether the files of a conditional include
+// section should be included.
+type ConditionEvaluator interface {
+	Evaluate(condition string) (bool, error)
+}
+
+// ConditionFunc allows to use a function as a ConditionEvaluator.
+type ConditionFunc func(condition string) (bool, error)
+
+// Evaluate calls f(condition).
+func (f ConditionFunc) Evaluate(condition string) (bool, error) {
+	return f(condition)
+}
+
+// Conditions is the default ConditionEvaluator, which supports conditions:
+//
+//   - "env:NAME" - the environment variable is set and not empty;
+//   - "env:NAME=pattern" - value of the environment variable matches the pattern;
+//   - "hostname:pattern" - the host name matches the pattern;
+//   - "path:pattern" - the working directory matches the pattern, patterns
+//     ending with "/" or "/**" match all the subdirectories too.
+//
+// Patterns use [filepath.Match] syntax. Nil functions default to the ones of
+// the os package, so they can be replaced in tests.
+type Conditions struct {
+	LookupEnv func(key string) (string, bool)
+	Hostname  func() (string, error)
+	Getwd     func() (string, error)
+}
+
+// Evaluate evaluates the condition.
+func (c Conditions) Evaluate(condition string) (bool, error) {
+	kind, arg, ok := strings.Cut(condition, ":")
+	if !ok {
+		return false, fmt.Errorf("invalid condition %q", condition)
+	}
+
+	switch kind {
+	case "env":
+		lookupEnv := c.LookupEnv
+		if lookupEnv == nil {
+			lookupEnv = os.LookupEnv
+		}
+		name, pattern, hasPattern := strings.Cut(arg, "=")
+		value, _ := lookupEnv(name)
+		if !hasPattern {
+			return value != "", nil
+		}
+		return filepath.Match(pattern, value)
+	case "hostname":
+		hostname := c.Hostname
+		if hostname == nil {
+			hostname = os.Hostname
+		}
+		host, err := hostname()
+		if err != nil {
+			return false, err
+		}
+		return filepath.Match(arg, host)
+	case "path":
+		getwd := c.Getwd
+		if getwd == nil {
+			getwd = os.Getwd
+		}
+		wd, err := getwd()
+		if err != nil {
+			return false, err
+		}
+		return matchPath(arg, wd)
+	}
+
+	return false, fmt.Errorf("unknown condition %q", kind)
+}
+
+// matchPath matches the path against the pattern, where trailing "/" or
+// "/**" of the pattern matches the directory and all of its subdirectories.
+func matchPath(pattern, path string) (bool, error) {
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	prefix, recursive := strings.CutSuffix(pattern, "/**")
+	if !recursive {
+		return filepath.Match(pattern, path)
+	}
+
+	for dir := path; ; dir = filepath.Dir(dir) {
+		ok, err := filepath.Match(prefix, dir)
+		if err != nil || ok {
+			return ok, err
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return false, nil
+		}
+	}
+}
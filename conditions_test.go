@@ -0,0 +1,99 @@
+package configparser_test
+
+import (
+	"path/filepath"
+
+	"github.com/bigkevmcd/go-configparser"
+
+	gc "gopkg.in/check.v1"
+)
+
+var fakeConditions = configparser.Conditions{
+	LookupEnv: func(key string) (string, bool) {
+		if key == "APP_ENV" {
+			return "prod", true
+		}
+		return "", false
+	},
+	Hostname: func() (string, error) { return "web-01", nil },
+	Getwd:    func() (string, error) { return "/srv/projects/app/src", nil },
+}
+
+// Conditions.Evaluate(condition) should evaluate the supported conditions.
+func (s *ConfigParserSuite) TestConditions(c *gc.C) {
+	for condition, expected := range map[string]bool{
+		"env:APP_ENV":           true,
+		"env:APP_ENV=prod":      true,
+		"env:APP_ENV=dev":       false,
+		"env:APP_DEBUG":         false,
+		"hostname:web-*":        true,
+		"hostname:db-*":         false,
+		"path:/srv/projects/":   true,
+		"path:/srv/*/app/**":    true,
+		"path:/srv/projects":    false,
+		"path:/srv/*/app/src":   true,
+		"path:/home/projects/":  false,
+		"path:/srv/projects/**": true,
+	} {
+		result, err := fakeConditions.Evaluate(condition)
+		c.Assert(err, gc.IsNil)
+		c.Assert(result, gc.Equals, expected, gc.Commentf("condition %q", condition))
+	}
+
+	_, err := fakeConditions.Evaluate("os:linux")
+	c.Assert(err, gc.ErrorMatches, `unknown condition "os"`)
+	_, err = fakeConditions.Evaluate("linux")
+	c.Assert(err, gc.ErrorMatches, `invalid condition "linux"`)
+}
+
+// [includeIf "condition"] sections should include files only if the
+// condition matches.
+func (s *ConfigParserSuite) TestConditionalIncludes(c *gc.C) {
+	dir := c.MkDir()
+	writeFiles(c, dir, map[string]string{
+		"app.cfg": `[db]
+host = localhost
+
+[includeIf "env:APP_ENV=prod"]
+path = prod.cfg
+
+[includeIf "hostname:db-*"]
+path = db.cfg
+`,
+		"prod.cfg": "[db]\nhost = db.example.com\n",
+		"db.cfg":   "[db]\nhost = 127.0.0.1\n",
+	})
+
+	p, err := configparser.ParseWithOptions(
+		filepath.Join(dir, "app.cfg"), configparser.ConditionalIncludes(fakeConditions),
+	)
+	c.Assert(err, gc.IsNil)
+	c.Assert(p.Sections(), gc.DeepEquals, []string{"db"})
+
+	v, err := p.Get("db", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "db.example.com")
+}
+
+// Conditional includes should accept a custom evaluator and report its errors.
+func (s *ConfigParserSuite) TestConditionalIncludesCustomEvaluator(c *gc.C) {
+	dir := c.MkDir()
+	writeFiles(c, dir, map[string]string{
+		"app.cfg": "[includeIf \"feature:x\"]\npath = x.cfg\n",
+		"x.cfg":   "[x]\n",
+	})
+	eval := configparser.ConditionFunc(func(condition string) (bool, error) {
+		return condition == "feature:x", nil
+	})
+
+	p, err := configparser.ParseWithOptions(
+		filepath.Join(dir, "app.cfg"), configparser.ConditionalIncludes(eval),
+	)
+	c.Assert(err, gc.IsNil)
+	c.Assert(p.Sections(), gc.DeepEquals, []string{"x"})
+
+	_, err = configparser.ParseWithOptions(
+		filepath.Join(dir, "app.cfg"), configparser.ConditionalIncludes(nil),
+	)
+	c.Assert(err, gc.ErrorMatches, `include condition "feature:x": unknown condition "feature"`)
+}
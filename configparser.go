@@ -2,10 +2,10 @@ package configparser
 
 import (
 	"bufio"
-	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"regexp"
 	"sort"
@@ -35,12 +35,26 @@ type Dict map[string]string
 // Config represents a Python style configuration file.
 type Config map[string]*Section
 
+// storage contains the sections and the change subscriptions of the
+// ConfigParser, which are shared with the profile views, see WithProfile.
+type storage struct {
+	config   Config
+	defaults *Section
+	// instances contains all occurrences of the repeated sections,
+	// see DuplicateSectionList.
+	instances     map[string][]*Section
+	subscriptions []*subscription
+	// inTx is set while a transaction is running, see Update.
+	inTx bool
+}
+
 // ConfigParser ties together a Config and default values for use in
 // interpolated configuration values.
 type ConfigParser struct {
-	config   Config
-	defaults *Section
-	opt      *options
+	*storage
+	opt *options
+	// source is the name of the Source being loaded.
+	source string
 }
 
 // Keys returns a sorted slice of keys
@@ -69,11 +83,16 @@ func getNoOptionError(section, option string) error {
 
 // New creates a new ConfigParser.
 func New() *ConfigParser {
-	return &ConfigParser{
-		config:   make(Config),
-		defaults: newSection(defaultSectionName),
-		opt:      defaultOptions(),
+	p := &ConfigParser{
+		storage: &storage{
+			config:    make(Config),
+			instances: make(map[string][]*Section),
+		},
+		opt: defaultOptions(),
 	}
+	p.defaults = p.newSection(defaultSectionName)
+
+	return p
 }
 
 // NewWithOptions creates a new ConfigParser with options.
@@ -83,11 +102,16 @@ func NewWithOptions(opts ...optFunc) *ConfigParser {
 		fn(opt)
 	}
 
-	return &ConfigParser{
-		config:   make(Config),
-		defaults: newSection(opt.defaultSection),
-		opt:      opt,
+	p := &ConfigParser{
+		storage: &storage{
+			config:    make(Config),
+			instances: make(map[string][]*Section),
+		},
+		opt: opt,
 	}
+	p.defaults = p.newSection(opt.defaultSection)
+
+	return p
 }
 
 // NewWithDefaults allows creation of a new ConfigParser with a pre-existing Dict.
@@ -100,6 +124,36 @@ func NewWithDefaults(defaults Dict, opts ...optFunc) (*ConfigParser, error) {
 	return p, nil
 }
 
+// clone returns a deep copy of the configuration, which shares options
+// but not subscriptions with the original.
+func (p *ConfigParser) clone() *ConfigParser {
+	c := &ConfigParser{
+		storage: &storage{
+			config:    make(Config, len(p.config)),
+			defaults:  p.defaults.clone(),
+			instances: make(map[string][]*Section, len(p.instances)),
+		},
+		opt: p.opt,
+	}
+	for name, s := range p.config {
+		c.config[name] = s.clone()
+	}
+	for name, instances := range p.instances {
+		cloned := make([]*Section, len(instances))
+		for i, s := range instances {
+			// First instance is the section itself.
+			if i == 0 {
+				cloned[i] = c.config[name]
+			} else {
+				cloned[i] = s.clone()
+			}
+		}
+		c.instances[name] = cloned
+	}
+
+	return c
+}
+
 // NewConfigParserFromFile creates a new ConfigParser struct populated from the
 // supplied filename.
 func NewConfigParserFromFile(filename string) (*ConfigParser, error) {
@@ -128,13 +182,19 @@ func ParseReaderWithOptions(in io.Reader, opts ...optFunc) (*ConfigParser, error
 
 // Parse takes a filename and parses it into a ConfigParser value.
 func Parse(filename string) (*ConfigParser, error) {
-	file, err := os.Open(filename)
-	if err != nil {
+	p := New()
+	if err := p.parseFile(filename, parseState{}); err != nil {
 		return nil, err
 	}
-	defer file.Close()
-	p, err := ParseReader(file)
-	if err != nil {
+	return p, nil
+}
+
+// ParseFS takes a file name within the file system and parses it into
+// a ConfigParser value with given options. The file system is used by all
+// file based features of the ConfigParser.
+func ParseFS(fsys fs.FS, name string, opts ...optFunc) (*ConfigParser, error) {
+	p := NewWithOptions(append(opts[:len(opts):len(opts)], FileSystem(fsys))...)
+	if err := p.parseFile(name, parseState{}); err != nil {
 		return nil, err
 	}
 	return p, nil
@@ -143,27 +203,56 @@ func Parse(filename string) (*ConfigParser, error) {
 // ParseWithOptions takes a filename and parses it into a ConfigParser value with given options.
 func ParseWithOptions(filename string, opts ...optFunc) (*ConfigParser, error) {
 	p := NewWithOptions(opts...)
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, err
-	}
-	err = p.ParseReader(bytes.NewReader(data))
+	err := p.parseFile(filename, parseState{})
 	return p, err
 }
 
-func writeSection(file *os.File, delimiter string, section *Section) error {
-	_, err := file.WriteString(fmt.Sprintf("[%s]\n", section.Name))
-	if err != nil {
-		return err
+func writeOption(w io.Writer, delimiter, option, value string) error {
+	_, err := fmt.Fprintf(w, "%s %s %s\n", option, delimiter, value)
+	return err
+}
+
+// formatValue escapes the inline comment prefixes within the value, see
+// InlineCommentPrefixes, and indents the continuation lines of multiline
+// values. The inline comment follows the first line, as the comments of
+// the continuation lines are not retained.
+func (p *ConfigParser) formatValue(value, comment string) string {
+	indent := "\t"
+	if len(p.opt.multilinePrefixes) > 0 && p.opt.multilinePrefixes[0] != "" {
+		indent = p.opt.multilinePrefixes[0]
+	}
+	lines := strings.Split(value, "\n")
+	for i, line := range lines {
+		lines[i] = p.opt.inlineCommentPrefixes.escape(line)
+	}
+	if comment != "" {
+		lines[0] += " " + comment
 	}
 
-	for _, option := range section.Options() {
-		_, err = file.WriteString(fmt.Sprintf("%s %s %s\n", option, delimiter, section.options[option]))
+	return strings.Join(lines, "\n"+indent)
+}
+
+func (p *ConfigParser) writeSection(w io.Writer, delimiter string, section *Section) error {
+	var err error
+	// Unnamed section is written without the header.
+	if section.Name != UnnamedSection {
+		_, err = fmt.Fprintf(w, "[%s]\n", section.Name)
 		if err != nil {
 			return err
 		}
 	}
-	_, err = file.WriteString("\n")
+
+	for _, option := range section.Options() {
+		// Repeated keys are written one line per value.
+		values, _ := section.getAll(option)
+		for i, value := range values {
+			err = writeOption(w, delimiter, option, p.formatValue(value, section.comment(option, i)))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	_, err = io.WriteString(w, "\n")
 	return err
 }
 
@@ -176,31 +265,79 @@ func (p *ConfigParser) SaveWithDelimiter(filename, delimiter string) error {
 	}
 	defer f.Close()
 
-	if len(p.defaults.Options()) > 0 {
-		err = writeSection(f, delimiter, p.defaults)
+	return p.WriteWithDelimiter(f, delimiter)
+}
+
+// WriteWithDelimiter writes the current state of the ConfigParser to w with
+// the specified delimiter.
+func (p *ConfigParser) WriteWithDelimiter(w io.Writer, delimiter string) error {
+	// Unnamed section goes first.
+	if s, err := p.section(UnnamedSection); err == nil && len(s.Options()) > 0 {
+		err := p.writeSection(w, delimiter, s)
 		if err != nil {
 			return err
 		}
 	}
 
-	for _, s := range p.Sections() {
-		err = writeSection(f, delimiter, p.config[s])
+	if len(p.defaults.Options()) > 0 {
+		err := p.writeSection(w, delimiter, p.defaults)
 		if err != nil {
 			return err
 		}
 	}
 
+	for _, s := range p.sectionNames() {
+		if p.isUnnamedSection(s) {
+			continue
+		}
+		for _, section := range p.sectionInstances(s) {
+			err := p.writeSection(w, delimiter, section)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
 // ParseReader parses data into ConfigParser from provided reader.
-func (p *ConfigParser) ParseReader(in io.Reader) (err error) {
+func (p *ConfigParser) ParseReader(in io.Reader) error {
+	return p.parse(in, parseState{})
+}
+
+// parseState describes the input being parsed.
+type parseState struct {
+	// source names the input for the recorded origins of the options,
+	// empty for readers.
+	source string
+	// stack contains absolute paths of the files being parsed.
+	stack []string
+	// depth is the include nesting level of the input.
+	depth int
+}
+
+// parse parses data from the reader.
+func (p *ConfigParser) parse(in io.Reader, st parseState) (err error) {
+	if p.inTx {
+		return ErrInTransaction
+	}
 	var (
 		reader = bufio.NewReader(in)
 
-		lineNo     int
-		key, value string
-		curSect    *Section
+		lineNo, keyLineNo   int
+		key, value, comment string
+		curSect             *Section
+		// Name of the option listing the files to include, set if current
+		// section is an include section.
+		includeOption string
+		// Set if current section is a conditional include section, which
+		// condition does not match.
+		skipSection bool
+		// Lines of the first occurrences of the keys in the sections.
+		keyLines = make(map[*Section]map[string]int)
+		// Lines of the first occurrences of the sections.
+		sectionLines = make(map[string]int)
 	)
 
 	keyValue, keyWNoValue, err := p.opt.compileRegex()
@@ -208,14 +345,82 @@ func (p *ConfigParser) ParseReader(in io.Reader) (err error) {
 		return err
 	}
 
+	// sectionSeen records the first occurrence of the section and returns
+	// its line, and true if the section was already seen in this input.
+	sectionSeen := func(section string) (int, bool) {
+		firstLine, repeated := sectionLines[p.sectionKey(section)]
+		if !repeated {
+			sectionLines[p.sectionKey(section)] = lineNo
+		}
+
+		return firstLine, repeated
+	}
+
+	addKey := func() error {
+		if skipSection {
+			return nil
+		}
+		if includeOption != "" {
+			if !strings.EqualFold(key, includeOption) {
+				return fmt.Errorf("unknown option %q in include section: %d", key, keyLineNo)
+			}
+			for _, pattern := range strings.Fields(value) {
+				if err := p.include(pattern, st); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+
+		origin := Origin{Source: st.source, Line: keyLineNo}
+		if origin.Source == "" {
+			origin.Source = p.source
+		}
+
+		lookupKey := curSect.safeKey(key)
+		if keyLines[curSect] == nil {
+			keyLines[curSect] = make(map[string]int)
+		}
+		firstLine, repeated := keyLines[curSect][lookupKey]
+		if !repeated {
+			keyLines[curSect][lookupKey] = keyLineNo
+		} else if p.opt.strict {
+			return fmt.Errorf(
+				"option %q in section %q already exists on line %d and strict flag was set: %d",
+				key, curSect.Name, firstLine, keyLineNo,
+			)
+		} else {
+			switch p.opt.duplicateKeys {
+			case DuplicateKeepFirst:
+				return nil
+			case DuplicateError:
+				return fmt.Errorf(
+					"option %q in section %q already defined on line %d: %d",
+					key, curSect.Name, firstLine, keyLineNo,
+				)
+			case DuplicateCollect:
+				curSect.appendWithOrigin(key, value, origin)
+				values, _ := curSect.getAll(key)
+				curSect.setComment(key, len(values)-1, comment)
+				return nil
+			}
+		}
+
+		// Add never returns an error.
+		_ = curSect.addWithOrigin(key, value, origin)
+		curSect.setComment(key, 0, comment)
+
+		return nil
+	}
+
 	for {
 		l, _, err := reader.ReadLine()
 		if err != nil {
 			// If error is end of file, then current key should be checked before return.
 			if errors.Is(err, io.EOF) {
 				if key != "" {
-					// Add never returns an error.
-					_ = curSect.Add(key, value)
+					return addKey()
 				}
 
 				return nil
@@ -244,7 +449,9 @@ func (p *ConfigParser) ParseReader(in io.Reader) (err error) {
 					return fmt.Errorf("missing section header: %d %s", lineNo, line)
 				}
 
-				value += "\n" + p.opt.inlineCommentPrefixes.Split(line)
+				// Comments of the continuation lines are not retained.
+				part, _ := p.opt.inlineCommentPrefixes.splitComment(line)
+				value += "\n" + part
 				// If current line is added as a value part, may continue.
 				continue
 			} else {
@@ -252,11 +459,12 @@ func (p *ConfigParser) ParseReader(in io.Reader) (err error) {
 				// multiline prefixes or it is an empty line which is not allowed within values,
 				// then it counts as the value parsing is finished and it can be added
 				// to the current section.
-				// Add never returns an error.
-				_ = curSect.Add(key, value)
+				if err := addKey(); err != nil {
+					return err
+				}
 
 				// Drop key-value pair to empty strings.
-				key, value = "", ""
+				key, value, comment = "", "", ""
 			}
 		}
 
@@ -265,17 +473,49 @@ func (p *ConfigParser) ParseReader(in io.Reader) (err error) {
 			continue
 		}
 
-		if match := sectionHeader.FindStringSubmatch(line); len(match) > 0 {
-			section := p.opt.inlineCommentPrefixes.Split(match[1])
-			if section == p.opt.defaultSection {
+		if pattern, ok := p.includeDirective(line); ok {
+			if err := p.include(pattern, st); err != nil {
+				return err
+			}
+
+			// Since the file was included on current line, may continue.
+			continue
+		}
+
+		if name, ok := p.opt.sectionMatcher(line); ok {
+			section := name
+			includeOption, skipSection = "", false
+			if p.opt.includeSection != "" && section == p.opt.includeSection {
+				// Options of the include section are not stored.
+				curSect = p.newSection(section)
+				includeOption = p.opt.includeOption
+			} else if condition, ok := p.includeCondition(section); ok {
+				matched, err := p.opt.conditions.Evaluate(condition)
+				if err != nil {
+					return fmt.Errorf("include condition %q: %w", condition, err)
+				}
+				// Options of the conditional include section are not stored.
+				curSect = p.newSection(section)
+				includeOption, skipSection = conditionalIncludeOption, !matched
+			} else if p.isDefaultSection(section) {
 				curSect = p.defaults
-			} else if _, present := p.config[section]; !present {
-				curSect = newSection(section)
-				p.config[section] = curSect
-			} else if p.opt.strict {
+			} else if err := p.validateSectionName(section); err != nil {
+				return fmt.Errorf("%w: %d", err, lineNo)
+			} else if firstLine, repeated := sectionSeen(section); repeated && p.opt.strict {
 				return fmt.Errorf(
-					"section %q already exists and strict flag was set", section,
+					"section %q already exists on line %d and strict flag was set: %d",
+					section, firstLine, lineNo,
 				)
+			} else if !p.HasSection(section) {
+				curSect = p.newSection(section)
+				p.config[p.sectionKey(section)] = curSect
+			} else if p.opt.duplicateSections == DuplicateSectionError {
+				return fmt.Errorf("section %q already exists: %d", section, lineNo)
+			} else if p.opt.duplicateSections == DuplicateSectionList {
+				curSect = p.newSection(section)
+				p.instances[p.sectionKey(section)] = append(p.sectionInstances(section), curSect)
+			} else {
+				curSect, _ = p.section(section)
 			}
 
 			// Since section was defined on current line, may continue.
@@ -284,29 +524,23 @@ func (p *ConfigParser) ParseReader(in io.Reader) (err error) {
 
 		if match := keyValue.FindStringSubmatch(line); len(match) > 0 {
 			if curSect == nil {
-				return fmt.Errorf("missing section header: %d %s", lineNo, line)
-			}
-			key = strings.TrimSpace(match[1])
-			if p.opt.strict {
-				if err := p.inOptions(key); err != nil {
-					return err
+				if curSect = p.unnamedSection(); curSect == nil {
+					return fmt.Errorf("missing section header: %d %s", lineNo, line)
 				}
 			}
+			key, keyLineNo = strings.TrimSpace(match[1]), lineNo
 
-			value = p.opt.inlineCommentPrefixes.Split(match[3])
+			value, comment = p.opt.inlineCommentPrefixes.splitComment(match[3])
 		} else if p.opt.allowNoValue {
 			if match = keyWNoValue.FindStringSubmatch(line); len(match) > 0 {
 				if curSect == nil {
-					return fmt.Errorf("missing section header: %d %s", lineNo, line)
-				}
-				key = strings.TrimSpace(match[1])
-				if p.opt.strict {
-					if err := p.inOptions(key); err != nil {
-						return err
+					if curSect = p.unnamedSection(); curSect == nil {
+						return fmt.Errorf("missing section header: %d %s", lineNo, line)
 					}
 				}
+				key, keyLineNo = strings.TrimSpace(match[1]), lineNo
 
-				value = p.opt.inlineCommentPrefixes.Split(match[4])
+				value, comment = p.opt.inlineCommentPrefixes.splitComment(match[4])
 			}
 		}
 	}
@@ -150,6 +150,20 @@ broken_option = this value will miss
 	})
 }
 
+// TestRepeatedSection tests that a repeated section header continues the
+// existing section.
+func (s *ConfigParserSuite) TestRepeatedSection(c *C) {
+	parsed, err := configparser.ParseReader(strings.NewReader("[a]\nkey = 1\n\n[b]\nkey = 2\n\n[a]\nother = 3\n"))
+	c.Assert(err, IsNil)
+
+	result, err := parsed.Items("a")
+	c.Assert(err, IsNil)
+	c.Assert(result, DeepEquals, configparser.Dict{"key": "1", "other": "3"})
+	result, err = parsed.Items("b")
+	c.Assert(err, IsNil)
+	c.Assert(result, DeepEquals, configparser.Dict{"key": "2"})
+}
+
 func (s *ConfigParserSuite) TestKeyValueRegexError(c *C) {
 	p := configparser.NewWithOptions(configparser.Delimiters("=-"))
 	err := p.ParseReader(strings.NewReader(""))
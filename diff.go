@@ -0,0 +1,299 @@
+package configparser
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// diffOptions allows to control Diff behavior.
+type diffOptions struct {
+	effective bool
+	from, to  string
+}
+
+type diffOptFunc func(*diffOptions)
+
+// EffectiveValues compares the values with the defaults merged in and
+// interpolations expanded instead of the raw values.
+func EffectiveValues(o *diffOptions) { o.effective = true }
+
+// DiffNames sets the names of the compared configurations used by the
+// text renderer.
+func DiffNames(from, to string) diffOptFunc {
+	return func(o *diffOptions) {
+		o.from, o.to = from, to
+	}
+}
+
+// DiffResult contains the differences between two configurations.
+type DiffResult struct {
+	From, To        string
+	AddedSections   []string
+	RemovedSections []string
+	// Changes holds the option changes sorted by section and option, the
+	// options of added and removed sections are reported as added and
+	// removed too.
+	Changes []Change
+}
+
+// Empty returns true if there are no differences.
+func (d *DiffResult) Empty() bool {
+	return len(d.AddedSections) == 0 && len(d.RemovedSections) == 0 && len(d.Changes) == 0
+}
+
+// Diff returns the differences to apply to a to get b.
+//
+// The DEFAULT section is compared as any other section, using the name of
+// the default section of a. Values collected from the repeated keys, see
+// DuplicateCollect, are compared one by one and reported joined with
+// newlines, effective values use the last values. Only the first instances
+// of the repeated sections, see DuplicateSectionList, are compared.
+func Diff(a, b *ConfigParser, opts ...diffOptFunc) (*DiffResult, error) {
+	o := &diffOptions{from: "a", to: "b"}
+	for _, fn := range opts {
+		fn(o)
+	}
+
+	d := &DiffResult{From: o.from, To: o.to}
+	defaultSection := a.opt.defaultSection
+
+	// DEFAULT goes first, other sections in sorted order. Sections are
+	// matched by their lookup keys and reported with their names in a.
+	sections := []string{defaultSection}
+	seen := map[string]bool{a.sectionKey(defaultSection): true}
+	for _, s := range append(a.sectionNames(), b.sectionNames()...) {
+		if !seen[a.sectionKey(s)] {
+			seen[a.sectionKey(s)] = true
+			sections = append(sections, s)
+		}
+	}
+	sort.Strings(sections[1:])
+
+	for _, section := range sections {
+		inA, inB := true, true
+		if section != defaultSection {
+			inA, inB = a.HasSection(section), b.HasSection(section)
+		}
+		switch {
+		case inA && !inB:
+			d.RemovedSections = append(d.RemovedSections, section)
+		case !inA && inB:
+			d.AddedSections = append(d.AddedSections, section)
+		}
+
+		var itemsA, itemsB valueLists
+		var err error
+		if inA {
+			if itemsA, err = a.diffItems(section, section == defaultSection, o.effective); err != nil {
+				return nil, err
+			}
+		}
+		if inB {
+			if itemsB, err = b.diffItems(section, section == defaultSection, o.effective); err != nil {
+				return nil, err
+			}
+		}
+		d.Changes = append(d.Changes, diffDicts(section, itemsA, itemsB)...)
+	}
+
+	return d, nil
+}
+
+// optionValues holds all values of an option, see Section.getAll, with
+// the name of the option as written.
+type optionValues struct {
+	name   string
+	values []string
+}
+
+// valueLists maps the lookup keys of the options to their values.
+type valueLists map[string]optionValues
+
+// keys returns the sorted lookup keys.
+func (l valueLists) keys() []string {
+	keys := make([]string, 0, len(l))
+	for k := range l {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// joined returns the values of the option joined with newlines.
+func (l valueLists) joined(key string) string {
+	return strings.Join(l[key].values, "\n")
+}
+
+// collected returns the values of the option if there are more than one,
+// or nil.
+func (l valueLists) collected(key string) []string {
+	if len(l[key].values) < 2 {
+		return nil
+	}
+
+	return l[key].values
+}
+
+// diffItems returns the items of the section to be compared.
+func (p *ConfigParser) diffItems(section string, isDefault, effective bool) (valueLists, error) {
+	if isDefault {
+		section = p.opt.defaultSection
+	}
+	if !effective {
+		return p.valueLists(section)
+	}
+
+	var items Dict
+	if !isDefault {
+		var err error
+		if items, err = p.ItemsWithDefaultsInterpolated(section); err != nil {
+			return nil, err
+		}
+	} else {
+		items = make(Dict)
+		for k := range p.Defaults() {
+			v, err := p.GetInterpolated(section, k)
+			if err != nil {
+				return nil, err
+			}
+			items[k] = v
+		}
+	}
+
+	lists := make(valueLists, len(items))
+	for k, v := range items {
+		lists[p.optionKey(k)] = optionValues{name: k, values: []string{v}}
+	}
+
+	return lists, nil
+}
+
+// valueLists returns all values of the options of the section, including
+// the values collected from the repeated keys.
+func (p *ConfigParser) valueLists(section string) (valueLists, error) {
+	s, err := p.section(section)
+	if err != nil {
+		return nil, err
+	}
+	lists := make(valueLists)
+	for _, k := range s.Options() {
+		values, _ := s.getAll(k)
+		lists[s.safeKey(k)] = optionValues{name: k, values: values}
+	}
+
+	return lists, nil
+}
+
+// diffDicts returns option changes between two versions of a section.
+// Options are matched by their lookup keys and reported with their names
+// in a, or in b for the added options.
+func diffDicts(section string, a, b valueLists) []Change {
+	changes := make([]Change, 0)
+	for _, k := range a.keys() {
+		v, present := b[k]
+		if !present {
+			changes = append(changes, Change{
+				Kind: OptionRemoved, Section: section, Option: a[k].name,
+				OldValue: a.joined(k), OldValues: a.collected(k),
+			})
+		} else if !slices.Equal(v.values, a[k].values) {
+			changes = append(changes, Change{
+				Kind: OptionChanged, Section: section, Option: a[k].name,
+				OldValue: a.joined(k), NewValue: b.joined(k),
+				OldValues: a.collected(k), NewValues: b.collected(k),
+			})
+		}
+	}
+	for _, k := range b.keys() {
+		if _, present := a[k]; !present {
+			changes = append(changes, Change{
+				Kind: OptionAdded, Section: section, Option: b[k].name,
+				NewValue: b.joined(k), NewValues: b.collected(k),
+			})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Option < changes[j].Option
+	})
+
+	return changes
+}
+
+// WriteUnified writes the differences in a unified diff like format.
+func (d *DiffResult) WriteUnified(w io.Writer) error {
+	if d.Empty() {
+		return nil
+	}
+
+	added := make(map[string]bool)
+	for _, s := range d.AddedSections {
+		added[s] = true
+	}
+	removed := make(map[string]bool)
+	for _, s := range d.RemovedSections {
+		removed[s] = true
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", d.From, d.To)
+
+	printed := make(map[string]bool)
+	header := func(name string) {
+		if printed[name] {
+			return
+		}
+		printed[name] = true
+		switch {
+		case added[name]:
+			fmt.Fprintf(&b, "+[%s]\n", name)
+		case removed[name]:
+			fmt.Fprintf(&b, "-[%s]\n", name)
+		default:
+			fmt.Fprintf(&b, " [%s]\n", name)
+		}
+	}
+	// Collected values are written one line per value, like the repeated
+	// keys, and multiline values with the continuation lines indented.
+	lines := func(marker, option, value string, values []string) {
+		if values == nil {
+			values = []string{value}
+		}
+		for _, value := range values {
+			value = strings.ReplaceAll(value, "\n", "\n"+marker+"\t")
+			fmt.Fprintf(&b, "%s%s = %s\n", marker, option, value)
+		}
+	}
+
+	for _, c := range d.Changes {
+		header(c.Section)
+		if c.Kind != OptionAdded {
+			lines("-", c.Option, c.OldValue, c.OldValues)
+		}
+		if c.Kind != OptionRemoved {
+			lines("+", c.Option, c.NewValue, c.NewValues)
+		}
+	}
+	// Sections without options have no changes to be reported with.
+	for _, s := range d.AddedSections {
+		header(s)
+	}
+	for _, s := range d.RemovedSections {
+		header(s)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// String returns the differences in a unified diff like format.
+func (d *DiffResult) String() string {
+	var b strings.Builder
+	// strings.Builder never returns an error.
+	_ = d.WriteUnified(&b)
+
+	return b.String()
+}
@@ -0,0 +1,157 @@
+package configparser_test
+
+import (
+	"strings"
+
+	"github.com/bigkevmcd/go-configparser"
+
+	gc "gopkg.in/check.v1"
+)
+
+func mustParse(c *gc.C, data string) *configparser.ConfigParser {
+	p, err := configparser.ParseReader(strings.NewReader(data))
+	c.Assert(err, gc.IsNil)
+
+	return p
+}
+
+// Diff(a, b) should report added and removed sections and options.
+func (s *ConfigParserSuite) TestDiff(c *gc.C) {
+	a := mustParse(c, "[DEFAULT]\nbase = /srv\n\n[db]\nhost = a\nport = 5432\n\n[old]\nkey = v\n")
+	b := mustParse(c, "[DEFAULT]\nbase = /srv\n\n[db]\nhost = b\nuser = admin\n\n[new]\n")
+
+	d, err := configparser.Diff(a, b)
+	c.Assert(err, gc.IsNil)
+	c.Assert(d.AddedSections, gc.DeepEquals, []string{"new"})
+	c.Assert(d.RemovedSections, gc.DeepEquals, []string{"old"})
+	c.Assert(d.Changes, gc.DeepEquals, []configparser.Change{
+		{Kind: configparser.OptionChanged, Section: "db", Option: "host", OldValue: "a", NewValue: "b"},
+		{Kind: configparser.OptionRemoved, Section: "db", Option: "port", OldValue: "5432"},
+		{Kind: configparser.OptionAdded, Section: "db", Option: "user", NewValue: "admin"},
+		{Kind: configparser.OptionRemoved, Section: "old", Option: "key", OldValue: "v"},
+	})
+
+	c.Assert(d.String(), gc.Equals, `--- a
++++ b
+ [db]
+-host = a
++host = b
+-port = 5432
++user = admin
+-[old]
+-key = v
++[new]
+`)
+}
+
+// Diff(a, b) should report no differences for equal configurations.
+func (s *ConfigParserSuite) TestDiffEqual(c *gc.C) {
+	d, err := configparser.Diff(s.p, s.p)
+	c.Assert(err, gc.IsNil)
+	c.Assert(d.Empty(), gc.Equals, true)
+	c.Assert(d.String(), gc.Equals, "")
+}
+
+// Diff(a, b) should match the options by their case-insensitive names.
+func (s *ConfigParserSuite) TestDiffOptionCase(c *gc.C) {
+	a := mustParse(c, "[DEFAULT]\nBase = /srv\n\n[s]\nHost = x\nPort = 1\n")
+	b := mustParse(c, "[DEFAULT]\nbase = /srv\n\n[s]\nhost = x\nport = 2\n")
+
+	d, err := configparser.Diff(a, b)
+	c.Assert(err, gc.IsNil)
+	c.Assert(d.Changes, gc.DeepEquals, []configparser.Change{
+		{Kind: configparser.OptionChanged, Section: "s", Option: "Port", OldValue: "1", NewValue: "2"},
+	})
+
+	d, err = configparser.Diff(a, b, configparser.EffectiveValues)
+	c.Assert(err, gc.IsNil)
+	c.Assert(d.Changes, gc.DeepEquals, []configparser.Change{
+		{Kind: configparser.OptionChanged, Section: "s", Option: "Port", OldValue: "1", NewValue: "2"},
+	})
+}
+
+// Diff(a, b) should match the sections by their lookup keys.
+func (s *ConfigParserSuite) TestDiffSectionTransform(c *gc.C) {
+	parse := func(data string) *configparser.ConfigParser {
+		p, err := configparser.ParseReaderWithOptions(
+			strings.NewReader(data), configparser.SectionTransform(strings.ToLower),
+		)
+		c.Assert(err, gc.IsNil)
+
+		return p
+	}
+
+	d, err := configparser.Diff(parse("[Server]\nhost = a\n"), parse("[server]\nhost = b\n"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(d.AddedSections, gc.HasLen, 0)
+	c.Assert(d.RemovedSections, gc.HasLen, 0)
+	c.Assert(d.Changes, gc.DeepEquals, []configparser.Change{
+		{Kind: configparser.OptionChanged, Section: "Server", Option: "host", OldValue: "a", NewValue: "b"},
+	})
+}
+
+// Diff(a, b, EffectiveValues) should compare values with defaults and
+// interpolation applied.
+func (s *ConfigParserSuite) TestDiffEffectiveValues(c *gc.C) {
+	a := mustParse(c, "[DEFAULT]\nbase = /srv\n\n[app]\ndir = %(base)s/app\n")
+	b := mustParse(c, "[DEFAULT]\nbase = /opt\n\n[app]\ndir = %(base)s/app\n")
+
+	d, err := configparser.Diff(a, b)
+	c.Assert(err, gc.IsNil)
+	c.Assert(d.Changes, gc.DeepEquals, []configparser.Change{
+		{Kind: configparser.OptionChanged, Section: "DEFAULT", Option: "base", OldValue: "/srv", NewValue: "/opt"},
+	})
+
+	d, err = configparser.Diff(a, b, configparser.EffectiveValues, configparser.DiffNames("git", "host"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(d.String(), gc.Equals, `--- git
++++ host
+ [DEFAULT]
+-base = /srv
++base = /opt
+ [app]
+-base = /srv
++base = /opt
+-dir = /srv/app
++dir = /opt/app
+`)
+}
+
+// Diff(a, b) should compare all values collected from the repeated keys.
+func (s *ConfigParserSuite) TestDiffCollectedValues(c *gc.C) {
+	parse := func(data string) *configparser.ConfigParser {
+		p, err := configparser.ParseReaderWithOptions(
+			strings.NewReader(data), configparser.DuplicateKeys(configparser.DuplicateCollect),
+		)
+		c.Assert(err, gc.IsNil)
+
+		return p
+	}
+	a := parse("[server]\nallow = 10.0.0.1\nallow = 10.0.0.2\n")
+	b := parse("[server]\nallow = 10.0.0.3\nallow = 10.0.0.2\n")
+
+	d, err := configparser.Diff(a, b)
+	c.Assert(err, gc.IsNil)
+	c.Assert(d.Changes, gc.DeepEquals, []configparser.Change{{
+		Kind: configparser.OptionChanged, Section: "server", Option: "allow",
+		OldValue: "10.0.0.1\n10.0.0.2", NewValue: "10.0.0.3\n10.0.0.2",
+		OldValues: []string{"10.0.0.1", "10.0.0.2"}, NewValues: []string{"10.0.0.3", "10.0.0.2"},
+	}})
+
+	// Collected values differ from a multiline value of the same lines.
+	d, err = configparser.Diff(a, parse("[server]\nallow = 10.0.0.1\n\t10.0.0.2\n"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(d.Changes, gc.DeepEquals, []configparser.Change{{
+		Kind: configparser.OptionChanged, Section: "server", Option: "allow",
+		OldValue: "10.0.0.1\n10.0.0.2", NewValue: "10.0.0.1\n10.0.0.2",
+		OldValues: []string{"10.0.0.1", "10.0.0.2"},
+	}})
+	c.Assert(d.String(), gc.Equals, `--- a
++++ b
+ [server]
+-allow = 10.0.0.1
+-allow = 10.0.0.2
++allow = 10.0.0.1
++	10.0.0.2
+`)
+}
@@ -0,0 +1,91 @@
+package configparser
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+)
+
+// ReadDir parses the files of the directory matching the pattern in lexical
+// order, so options of later files override the earlier ones.
+//
+// Returns the list of parsed files, see ReadDirs for the details.
+func (p *ConfigParser) ReadDir(dir, pattern string) ([]string, error) {
+	return p.ReadDirs(pattern, dir)
+}
+
+// ReadDirs parses the files matching the pattern from all of the directories
+// in lexical order of their names, in the same way as systemd drop-in
+// directories are handled.
+//
+// A file in a later directory replaces the file with the same name in the
+// earlier ones. Empty files and symlinks to /dev/null mask the files with the
+// same name and are not parsed. Missing directories are skipped.
+//
+// Returns the list of parsed files.
+func (p *ConfigParser) ReadDirs(pattern string, dirs ...string) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	files := make(Dict)
+	for _, dir := range dirs {
+		entries, err := p.opt.fsys.ReadDir(dir)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		for _, e := range entries {
+			// Pattern was checked, so there can't be an error.
+			if ok, _ := path.Match(pattern, e.Name()); ok {
+				files[e.Name()] = p.opt.fsys.Join(dir, e.Name())
+			}
+		}
+	}
+
+	applied := make([]string, 0, len(files))
+	for _, name := range files.Keys() {
+		filename := files[name]
+		info, err := p.opt.fsys.Stat(filename)
+		if err != nil {
+			return applied, err
+		}
+		if info.IsDir() || isMasked(info) {
+			continue
+		}
+
+		if err := p.parseFile(filename, parseState{}); err != nil {
+			return applied, err
+		}
+		applied = append(applied, filename)
+	}
+
+	return applied, nil
+}
+
+// isMasked checks if the file is empty or a device, e.g. /dev/null.
+func isMasked(info fs.FileInfo) bool {
+	return info.Size() == 0 || info.Mode()&fs.ModeDevice != 0
+}
+
+// ReadFiles parses the named files in the given order, missing files are
+// skipped the same way as Python ConfigParser.read does.
+//
+// Returns the list of parsed files.
+func (p *ConfigParser) ReadFiles(filenames ...string) ([]string, error) {
+	read := make([]string, 0, len(filenames))
+	for _, filename := range filenames {
+		if _, err := p.opt.fsys.Stat(filename); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err := p.parseFile(filename, parseState{}); err != nil {
+			return read, err
+		}
+		read = append(read, filename)
+	}
+
+	return read, nil
+}
@@ -0,0 +1,63 @@
+package configparser_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/bigkevmcd/go-configparser"
+
+	gc "gopkg.in/check.v1"
+)
+
+// ReadDir(dir, pattern) should parse matching files in lexical order.
+func (s *ConfigParserSuite) TestReadDir(c *gc.C) {
+	dir := c.MkDir()
+	writeFiles(c, dir, map[string]string{
+		"20-override.conf": "[db]\nhost = db.example.com\n",
+		"10-base.conf":     "[db]\nhost = localhost\nport = 5432\n",
+		"30-empty.conf":    "",
+		"README":           "not a config",
+	})
+	c.Assert(os.Mkdir(filepath.Join(dir, "40-dir.conf"), 0o755), gc.IsNil)
+
+	p := configparser.New()
+	files, err := p.ReadDir(dir, "*.conf")
+	c.Assert(err, gc.IsNil)
+	c.Assert(files, gc.DeepEquals, []string{
+		filepath.Join(dir, "10-base.conf"),
+		filepath.Join(dir, "20-override.conf"),
+	})
+
+	items, err := p.Items("db")
+	c.Assert(err, gc.IsNil)
+	c.Assert(items, gc.DeepEquals, configparser.Dict{"host": "db.example.com", "port": "5432"})
+}
+
+// ReadDirs(pattern, dirs...) should let later directories replace and
+// mask the files of the earlier ones.
+func (s *ConfigParserSuite) TestReadDirsMasking(c *gc.C) {
+	dir := c.MkDir()
+	vendor, local := filepath.Join(dir, "usr"), filepath.Join(dir, "etc")
+	writeFiles(c, vendor, map[string]string{
+		"10-db.conf":    "[db]\nhost = localhost\n",
+		"20-cache.conf": "[cache]\nsize = 10\n",
+		"30-log.conf":   "[log]\nlevel = info\n",
+	})
+	writeFiles(c, local, map[string]string{
+		"10-db.conf":  "[db]\nhost = db.example.com\n",
+		"30-log.conf": "",
+	})
+	c.Assert(os.Symlink(os.DevNull, filepath.Join(local, "20-cache.conf")), gc.IsNil)
+
+	p := configparser.New()
+	files, err := p.ReadDirs("*.conf", vendor, local, filepath.Join(dir, "missing"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(files, gc.DeepEquals, []string{filepath.Join(local, "10-db.conf")})
+	c.Assert(p.Sections(), gc.DeepEquals, []string{"db"})
+}
+
+// ReadDir(dir, pattern) should fail for malformed patterns.
+func (s *ConfigParserSuite) TestReadDirBadPattern(c *gc.C) {
+	_, err := configparser.New().ReadDir(c.MkDir(), "[")
+	c.Assert(err, gc.ErrorMatches, "syntax error in pattern")
+}
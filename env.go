@@ -0,0 +1,114 @@
+package configparser
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// envOptions allows to control ApplyEnv behavior.
+type envOptions struct {
+	separator      string
+	fold           func(string) string
+	createSections bool
+	defaults       bool
+	environ        []string
+}
+
+type envOptFunc func(*envOptions)
+
+// EnvSeparator sets the separator of the section and option names within
+// the variable names, defaults to "__".
+func EnvSeparator(sep string) envOptFunc {
+	return func(o *envOptions) {
+		o.separator = sep
+	}
+}
+
+// EnvCaseFold sets the function applied to the section and option names,
+// defaults to strings.ToLower.
+func EnvCaseFold(fn func(string) string) envOptFunc {
+	return func(o *envOptions) {
+		o.fold = fn
+	}
+}
+
+// EnvPreserveCase keeps the section and option names as written.
+func EnvPreserveCase(o *envOptions) { o.fold = func(s string) string { return s } }
+
+// EnvCreateSections adds the missing sections, otherwise variables of the
+// missing sections are skipped.
+func EnvCreateSections(o *envOptions) { o.createSections = true }
+
+// EnvDefaults sets the variables without the separator as the options of
+// the default section, otherwise they are skipped.
+func EnvDefaults(o *envOptions) { o.defaults = true }
+
+// EnvVars sets the "NAME=value" variables to be scanned instead of the
+// environment of the process.
+func EnvVars(vars []string) envOptFunc {
+	return func(o *envOptions) {
+		o.environ = vars
+	}
+}
+
+// ApplyEnv sets the options from the environment variables with the prefix.
+// Remaining part of the name is split with the separator into the section
+// and option names, e.g. APP_DATABASE__HOST sets the option "host" of
+// the section "database" with the prefix "APP_".
+//
+// Options record "env" as their origin, unless the variables are applied
+// as a Source.
+func (p *ConfigParser) ApplyEnv(prefix string, opts ...envOptFunc) error {
+	o := &envOptions{separator: "__", fold: strings.ToLower}
+	for _, fn := range opts {
+		fn(o)
+	}
+	environ := o.environ
+	if environ == nil {
+		environ = os.Environ()
+	}
+	environ = append([]string(nil), environ...)
+	sort.Strings(environ)
+
+	if p.source == "" {
+		p.source = envSourceName
+		defer func() { p.source = "" }()
+	}
+
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		section, option, ok := strings.Cut(strings.TrimPrefix(name, prefix), o.separator)
+		if !ok {
+			if !o.defaults {
+				continue
+			}
+			section, option = p.opt.defaultSection, section
+		}
+		// Empty names are skipped before folding.
+		if section == "" || option == "" {
+			continue
+		}
+		if ok {
+			section = o.fold(section)
+		}
+		option = o.fold(option)
+
+		if !p.isDefaultSection(section) && !p.HasSection(section) {
+			if !o.createSections {
+				continue
+			}
+			if err := p.AddSection(section); err != nil {
+				return err
+			}
+		}
+		if err := p.Set(section, option, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package configparser_test
+
+import (
+	"strings"
+
+	"github.com/bigkevmcd/go-configparser"
+
+	gc "gopkg.in/check.v1"
+)
+
+var testEnv = []string{
+	"APP_FOLLOWER__MAX_BUILD_TIME=300",
+	"APP_DB__HOST=db.example.com",
+	"APP_LOG_LEVEL=debug",
+	"OTHER_FOLLOWER__LOG_DIR=/tmp",
+	"APP_FOLLOWER__=ignored",
+}
+
+// ApplyEnv(prefix) should set the options of existing sections from the
+// environment and record "env" as their origin.
+func (s *ConfigParserSuite) TestApplyEnv(c *gc.C) {
+	err := s.p.ApplyEnv("APP_", configparser.EnvVars(testEnv))
+	c.Assert(err, gc.IsNil)
+
+	v, err := s.p.Get("follower", "max_build_time")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "300")
+	origin, err := s.p.Origin("follower", "max_build_time")
+	c.Assert(err, gc.IsNil)
+	c.Assert(origin, gc.Equals, configparser.Origin{Source: "env"})
+
+	c.Assert(s.p.HasSection("db"), gc.Equals, false)
+	_, err = s.p.Get("DEFAULT", "log_level")
+	c.Assert(err, gc.NotNil)
+	v, err = s.p.Get("follower", "log_dir")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "%(base_dir)s/logs")
+}
+
+// ApplyEnv(prefix, opts...) should create sections and target the defaults
+// if configured.
+func (s *ConfigParserSuite) TestApplyEnvCreateSectionsAndDefaults(c *gc.C) {
+	err := s.p.ApplyEnv("APP_",
+		configparser.EnvVars(testEnv),
+		configparser.EnvCreateSections,
+		configparser.EnvDefaults,
+	)
+	c.Assert(err, gc.IsNil)
+
+	v, err := s.p.Get("db", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "db.example.com")
+	v, err = s.p.Get("DEFAULT", "log_level")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "debug")
+}
+
+// ApplyEnv(prefix, opts...) should use the custom separator and case folding,
+// which is not applied to the empty names.
+func (s *ConfigParserSuite) TestApplyEnvSeparatorAndCase(c *gc.C) {
+	p := configparser.New()
+	assertSuccessful(c, p.AddSection("Database"))
+
+	err := p.ApplyEnv("APP_",
+		configparser.EnvVars([]string{
+			"APP_database_Host=localhost", "APP_DATABASE_PORT=5432", "APP__Host=empty", "APP_Database_=empty",
+		}),
+		configparser.EnvSeparator("_"),
+		configparser.EnvCaseFold(func(s string) string {
+			return strings.ToUpper(s[:1]) + strings.ToLower(s[1:])
+		}),
+	)
+	c.Assert(err, gc.IsNil)
+
+	items, err := p.Items("Database")
+	c.Assert(err, gc.IsNil)
+	c.Assert(items, gc.DeepEquals, configparser.Dict{"Host": "localhost", "Port": "5432"})
+
+	err = p.ApplyEnv("APP_",
+		configparser.EnvVars([]string{"APP_Database_Port=5433"}),
+		configparser.EnvSeparator("_"),
+		configparser.EnvPreserveCase,
+	)
+	c.Assert(err, gc.IsNil)
+	v, err := p.Get("Database", "port")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "5433")
+}
@@ -0,0 +1,110 @@
+package configparser
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// fileSystem provides access to the files for all file based features.
+type fileSystem interface {
+	fs.StatFS
+	fs.ReadDirFS
+	fs.GlobFS
+
+	// Resolve returns the name of the file referenced from the given file,
+	// relative names are resolved against its directory.
+	Resolve(from, name string) string
+	// Join joins the directory and the file name.
+	Join(dir, name string) string
+	// Abs returns an absolute representation of the name, which is used to
+	// identify the file.
+	Abs(name string) (string, error)
+	// Contains checks if the named file is within the root directory.
+	Contains(root, name string) (bool, error)
+}
+
+// osFileSystem uses the files of the operating system.
+type osFileSystem struct{}
+
+func (osFileSystem) Open(name string) (fs.File, error) { return os.Open(name) }
+
+func (osFileSystem) Stat(name string) (fs.FileInfo, error) { return os.Stat(name) }
+
+func (osFileSystem) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+
+func (osFileSystem) Glob(pattern string) ([]string, error) { return filepath.Glob(pattern) }
+
+func (osFileSystem) Resolve(from, name string) string {
+	if from == "" || filepath.IsAbs(name) {
+		return name
+	}
+
+	return filepath.Join(filepath.Dir(from), name)
+}
+
+func (osFileSystem) Join(dir, name string) string { return filepath.Join(dir, name) }
+
+func (osFileSystem) Abs(name string) (string, error) { return filepath.Abs(name) }
+
+func (osFileSystem) Contains(root, name string) (bool, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return false, err
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return false, err
+	}
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return false, err
+	}
+	// Missing files are reported when opened.
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// ioFileSystem uses the files of an fs.FS, names starting with "/" are
+// relative to its root.
+type ioFileSystem struct {
+	fsys fs.FS
+}
+
+func (f ioFileSystem) Open(name string) (fs.File, error) { return f.fsys.Open(name) }
+
+func (f ioFileSystem) Stat(name string) (fs.FileInfo, error) { return fs.Stat(f.fsys, name) }
+
+func (f ioFileSystem) ReadDir(name string) ([]fs.DirEntry, error) { return fs.ReadDir(f.fsys, name) }
+
+func (f ioFileSystem) Glob(pattern string) ([]string, error) { return fs.Glob(f.fsys, pattern) }
+
+func (ioFileSystem) Resolve(from, name string) string {
+	if strings.HasPrefix(name, "/") {
+		return path.Clean(strings.TrimLeft(name, "/"))
+	}
+	if from == "" {
+		return name
+	}
+
+	return path.Join(path.Dir(from), name)
+}
+
+func (ioFileSystem) Join(dir, name string) string { return path.Join(dir, name) }
+
+func (ioFileSystem) Abs(name string) (string, error) { return path.Clean(name), nil }
+
+func (ioFileSystem) Contains(root, name string) (bool, error) {
+	root, name = path.Clean(root), path.Clean(name)
+
+	return root == "." || name == root || strings.HasPrefix(name, root+"/"), nil
+}
@@ -0,0 +1,66 @@
+package configparser_test
+
+import (
+	"io/fs"
+	"testing/fstest"
+
+	"github.com/bigkevmcd/go-configparser"
+
+	gc "gopkg.in/check.v1"
+)
+
+var testFS = fstest.MapFS{
+	"etc/app.cfg":              {Data: []byte("[app]\nname = app\n%include conf.d/*.cfg\n%include /shared/log.cfg\n")},
+	"etc/conf.d/10-db.cfg":     {Data: []byte("[db]\nhost = localhost\n")},
+	"etc/conf.d/20-db.cfg":     {Data: []byte("[db]\nport = 5432\n")},
+	"etc/conf.d/30-masked.cfg": {Data: []byte("")},
+	"etc/local.d/10-db.cfg":    {Data: []byte("[db]\nhost = db.example.com\n")},
+	"etc/local.d/20-db.cfg":    {Mode: fs.ModeDevice | fs.ModeCharDevice},
+	"shared/log.cfg":           {Data: []byte("[log]\nlevel = info\n")},
+	"etc/cycle.cfg":            {Data: []byte("[cycle]\n%include ../etc/cycle.cfg\n")},
+}
+
+// ParseFS(fsys, name) should parse the file and its includes from the
+// file system.
+func (s *ConfigParserSuite) TestParseFS(c *gc.C) {
+	p, err := configparser.ParseFS(testFS, "etc/app.cfg", configparser.AllowIncludes)
+	c.Assert(err, gc.IsNil)
+	c.Assert(p.Sections(), gc.DeepEquals, []string{"app", "db", "log"})
+
+	items, err := p.Items("db")
+	c.Assert(err, gc.IsNil)
+	c.Assert(items, gc.DeepEquals, configparser.Dict{"host": "localhost", "port": "5432"})
+
+	origin, err := p.Origin("log", "level")
+	c.Assert(err, gc.IsNil)
+	c.Assert(origin, gc.Equals, configparser.Origin{Source: "shared/log.cfg", Line: 2})
+}
+
+// ParseFS(fsys, name) should detect include cycles and respect the include root.
+func (s *ConfigParserSuite) TestParseFSIncludeErrors(c *gc.C) {
+	_, err := configparser.ParseFS(testFS, "etc/cycle.cfg", configparser.AllowIncludes)
+	c.Assert(err, gc.ErrorMatches, `include "etc/cycle.cfg": include cycle: etc/cycle.cfg -> etc/cycle.cfg`)
+
+	_, err = configparser.ParseFS(
+		testFS, "etc/app.cfg", configparser.AllowIncludes, configparser.IncludeRoot("etc"),
+	)
+	c.Assert(err, gc.ErrorMatches, `include "shared/log.cfg" is outside of the root directory "etc"`)
+
+	_, err = configparser.ParseFS(testFS, "missing.cfg")
+	c.Assert(err, gc.ErrorMatches, "open missing.cfg: file does not exist")
+}
+
+// ReadDirs(pattern, dirs...) and ReadFiles(names...) should use the file
+// system set with FileSystem option.
+func (s *ConfigParserSuite) TestFileSystemOpt(c *gc.C) {
+	p := configparser.NewWithOptions(configparser.FileSystem(testFS))
+
+	files, err := p.ReadDirs("*.cfg", "etc/conf.d", "etc/local.d")
+	c.Assert(err, gc.IsNil)
+	c.Assert(files, gc.DeepEquals, []string{"etc/local.d/10-db.cfg"})
+
+	files, err = p.ReadFiles("missing.cfg", "shared/log.cfg")
+	c.Assert(err, gc.IsNil)
+	c.Assert(files, gc.DeepEquals, []string{"shared/log.cfg"})
+	c.Assert(p.Sections(), gc.DeepEquals, []string{"db", "log"})
+}
@@ -0,0 +1,186 @@
+package configparser
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// flagsSourceName is the name of the Source returned by BindFlags.
+const flagsSourceName = "flags"
+
+// Overrides is a repeatable command-line flag collecting
+// "section.option=value" overrides.
+type Overrides []string
+
+// OverridesFlag registers the repeatable overrides flag, e.g. -o, on the
+// flag set. Overrides are applied with Apply after the flags are parsed.
+func OverridesFlag(fs *flag.FlagSet, name, usage string) *Overrides {
+	o := new(Overrides)
+	fs.Var(o, name, usage)
+
+	return o
+}
+
+// String returns the overrides separated by commas.
+func (o *Overrides) String() string {
+	if o == nil {
+		return ""
+	}
+
+	return strings.Join(*o, ",")
+}
+
+// Set validates and adds the override.
+func (o *Overrides) Set(value string) error {
+	if _, _, _, err := parseOverride(value); err != nil {
+		return err
+	}
+	*o = append(*o, value)
+
+	return nil
+}
+
+// Source returns the overrides as a Source, see OverrideSource.
+func (o *Overrides) Source() Source {
+	return OverrideSource(*o...)
+}
+
+// Apply sets the overrides, adding the missing sections.
+func (o *Overrides) Apply(p *ConfigParser) error {
+	return p.Load(o.Source())
+}
+
+// FlagBindings contains command-line flags registered for the options.
+type FlagBindings struct {
+	fs      *flag.FlagSet
+	p       *ConfigParser
+	options map[string][2]string
+}
+
+// BindFlags registers a flag named "section.option" on the flag set for each
+// option of the ConfigParser, including the defaults. Current values are used
+// as the defaults of the flags and define their types: integer, float,
+// boolean or string. Flags already defined on the flag set are skipped.
+//
+// Values of the flags set on the command-line are applied with Apply after
+// the flags are parsed, as written on the command-line.
+func BindFlags(fs *flag.FlagSet, p *ConfigParser) *FlagBindings {
+	b := &FlagBindings{fs: fs, p: p, options: make(map[string][2]string)}
+
+	sections := append([]string{p.opt.defaultSection}, p.Sections()...)
+	for _, section := range sections {
+		// Section exists, so there can't be an error.
+		items, _ := p.Items(section)
+		for _, option := range items.Keys() {
+			name := section + "." + option
+			if fs.Lookup(name) != nil {
+				continue
+			}
+			b.options[name] = [2]string{section, option}
+			defineFlag(fs, name, items[option], fmt.Sprintf("option %q of section %q", option, section))
+		}
+	}
+
+	return b
+}
+
+// errFlagSyntax is returned for flag values not matching the type of the
+// option, as the flag package does.
+var errFlagSyntax = errors.New("parse error")
+
+// optionFlag is a flag.Value validating the values with the parse function,
+// but keeping them as written on the command-line.
+type optionFlag struct {
+	value  string
+	parse  func(string) error
+	isBool bool
+}
+
+// String returns the value of the flag.
+func (f *optionFlag) String() string {
+	if f == nil {
+		return ""
+	}
+
+	return f.value
+}
+
+// Set validates and stores the value.
+func (f *optionFlag) Set(value string) error {
+	if f.parse != nil {
+		if err := f.parse(value); err != nil {
+			return errFlagSyntax
+		}
+	}
+	f.value = value
+
+	return nil
+}
+
+// IsBoolFlag allows boolean flags without a value on the command-line.
+func (f *optionFlag) IsBoolFlag() bool { return f.isBool }
+
+// defineFlag defines the flag with the type detected from the value.
+func defineFlag(fs *flag.FlagSet, name, value, usage string) {
+	f := &optionFlag{value: value}
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		f.parse = parseIntFlag
+	} else if _, err := strconv.ParseFloat(value, 64); err == nil {
+		f.parse = parseFloatFlag
+	} else if _, present := boolMapping[strings.ToLower(value)]; present {
+		f.parse, f.isBool = parseBoolFlag, true
+	}
+	fs.Var(f, name, usage)
+}
+
+// parseIntFlag accepts decimal integers, with leading zeros too, and
+// integers with a base prefix.
+func parseIntFlag(value string) error {
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return nil
+	}
+	_, err := strconv.ParseInt(value, 0, 64)
+
+	return err
+}
+
+// parseFloatFlag accepts floating point numbers.
+func parseFloatFlag(value string) error {
+	_, err := strconv.ParseFloat(value, 64)
+
+	return err
+}
+
+// parseBoolFlag accepts the boolean values of GetBool.
+func parseBoolFlag(value string) error {
+	if _, present := boolMapping[strings.ToLower(value)]; !present {
+		return fmt.Errorf("invalid boolean %q", value)
+	}
+
+	return nil
+}
+
+// Name returns the name of the source.
+func (b *FlagBindings) Name() string { return flagsSourceName }
+
+// Load sets the options of the flags set on the command-line.
+func (b *FlagBindings) Load(p *ConfigParser) error {
+	var err error
+	b.fs.Visit(func(f *flag.Flag) {
+		key, bound := b.options[f.Name]
+		if !bound || err != nil {
+			return
+		}
+		err = p.Set(key[0], key[1], f.Value.String())
+	})
+
+	return err
+}
+
+// Apply sets the options of the flags set on the command-line.
+func (b *FlagBindings) Apply() error {
+	return b.p.Load(b)
+}
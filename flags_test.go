@@ -0,0 +1,109 @@
+package configparser_test
+
+import (
+	"flag"
+	"io"
+
+	"github.com/bigkevmcd/go-configparser"
+
+	gc "gopkg.in/check.v1"
+)
+
+// OverridesFlag(fs, name, usage) should collect repeated overrides and apply
+// them to the ConfigParser.
+func (s *ConfigParserSuite) TestOverridesFlag(c *gc.C) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	overrides := configparser.OverridesFlag(fs, "o", "override option")
+
+	err := fs.Parse([]string{"-o", "follower.max_build_time=300", "-o", "db.host=localhost"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(overrides.Apply(s.p), gc.IsNil)
+
+	v, err := s.p.Get("follower", "max_build_time")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "300")
+	v, err = s.p.Get("db", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "localhost")
+	origin, err := s.p.Origin("db", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(origin, gc.Equals, configparser.Origin{Source: "overrides"})
+}
+
+// OverridesFlag(fs, name, usage) should reject malformed overrides.
+func (s *ConfigParserSuite) TestOverridesFlagInvalid(c *gc.C) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configparser.OverridesFlag(fs, "o", "override option")
+
+	err := fs.Parse([]string{"-o", "host=localhost"})
+	c.Assert(err, gc.ErrorMatches, `invalid value "host=localhost" for flag -o: invalid override .*`)
+}
+
+// BindFlags(fs, p) should register typed flags for the options and apply
+// the ones set on the command-line.
+func (s *ConfigParserSuite) TestBindFlags(c *gc.C) {
+	p, err := configparser.Load(configparser.BytesSource(
+		"app.cfg", []byte("[DEFAULT]\ndebug = off\n\n[database]\nhost = localhost\nport = 5432\nratio = 0.5\n"),
+	))
+	c.Assert(err, gc.IsNil)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	bindings := configparser.BindFlags(fs, p)
+
+	port := fs.Lookup("database.port")
+	c.Assert(port, gc.NotNil)
+	c.Assert(port.DefValue, gc.Equals, "5432")
+
+	err = fs.Parse([]string{"--database.port=5433", "--DEFAULT.debug", "--database.ratio", "0.75"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(bindings.Apply(), gc.IsNil)
+
+	items, err := p.ItemsWithDefaults("database")
+	c.Assert(err, gc.IsNil)
+	c.Assert(items, gc.DeepEquals, configparser.Dict{
+		"debug": "true",
+		"host":  "localhost",
+		"port":  "5433",
+		"ratio": "0.75",
+	})
+	origin, err := p.Origin("database", "port")
+	c.Assert(err, gc.IsNil)
+	c.Assert(origin, gc.Equals, configparser.Origin{Source: "flags"})
+	origin, err = p.Origin("database", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(origin, gc.Equals, configparser.Origin{Source: "app.cfg", Line: 5})
+
+	err = fs.Parse([]string{"--database.port=none"})
+	c.Assert(err, gc.ErrorMatches, `invalid value "none" for flag -database.port: parse error`)
+}
+
+// BindFlags(fs, p) should apply the values as written on the command-line.
+func (s *ConfigParserSuite) TestBindFlagsRawValues(c *gc.C) {
+	p, err := configparser.Load(configparser.BytesSource(
+		"app.cfg", []byte("[files]\nmode = 0755\nsize = 8\nratio = 1.25\nenabled = yes\n"),
+	))
+	c.Assert(err, gc.IsNil)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	bindings := configparser.BindFlags(fs, p)
+	c.Assert(fs.Lookup("files.mode").DefValue, gc.Equals, "0755")
+
+	err = fs.Parse([]string{"--files.mode=0644", "--files.size=0x10", "--files.ratio=1.50", "--files.enabled=off"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(bindings.Apply(), gc.IsNil)
+
+	items, err := p.Items("files")
+	c.Assert(err, gc.IsNil)
+	c.Assert(items, gc.DeepEquals, configparser.Dict{
+		"mode":    "0644",
+		"size":    "0x10",
+		"ratio":   "1.50",
+		"enabled": "off",
+	})
+
+	err = fs.Parse([]string{"--files.ratio=high"})
+	c.Assert(err, gc.ErrorMatches, `invalid value "high" for flag -files.ratio: parse error`)
+	err = fs.Parse([]string{"--files.enabled=maybe"})
+	c.Assert(err, gc.ErrorMatches, `invalid boolean value "maybe" for -files.enabled: parse error`)
+}
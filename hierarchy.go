@@ -0,0 +1,154 @@
+package configparser
+
+import (
+	"sort"
+	"strings"
+)
+
+// chain returns the sections consulted by the lookups of the named section,
+// the most specific first and the defaults last.
+//
+// Returns an error if the section does not exist.
+// Returns an error if the inherited sections do not exist or form a cycle.
+func (p *ConfigParser) chain(section string) ([]*Section, error) {
+	s, err := p.section(section)
+	if err != nil {
+		return nil, err
+	}
+	defaults := []*Section{p.defaults}
+	if overlay := p.overlay(p.opt.defaultSection); overlay != nil {
+		defaults = []*Section{overlay, p.defaults}
+	}
+	if s == p.defaults {
+		return defaults, nil
+	}
+
+	chain, err := p.lineage(section)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(chain, defaults...), nil
+}
+
+// ownSections returns the number of the sections at the start of the chain
+// of the named section, which belong to the section itself: the section and
+// its overlay, if a profile is active.
+func (p *ConfigParser) ownSections(section string) int {
+	if p.overlay(section) != nil {
+		return 2
+	}
+
+	return 1
+}
+
+// parent returns the name of the nearest existing parent section or empty
+// string if there is none or the hierarchical sections are disabled.
+func (p *ConfigParser) parent(section string) string {
+	sep := p.opt.hierarchySeparator
+	if sep == "" {
+		return ""
+	}
+	for {
+		i := strings.LastIndex(section, sep)
+		if i <= 0 {
+			return ""
+		}
+		section = section[:i]
+		if p.HasSection(section) {
+			return section
+		}
+	}
+}
+
+// Children returns a sorted list of the sections, whose nearest existing
+// parent is the named section, see HierarchicalSections.
+//
+// Returns an error if the section does not exist.
+func (p *ConfigParser) Children(section string) ([]string, error) {
+	if !p.HasSection(section) {
+		return nil, getNoSectionError(section)
+	}
+	children := make([]string, 0)
+	for _, s := range p.Sections() {
+		if parent := p.parent(s); parent != "" && p.sectionKey(parent) == p.sectionKey(section) {
+			children = append(children, s)
+		}
+	}
+
+	return children, nil
+}
+
+// Tree returns the sections, excluding DEFAULT, as nested maps split by
+// the hierarchy separator, see HierarchicalSections. Options are stored
+// as strings and child sections as map[string]any, which take precedence
+// over the options of the same name.
+func (p *ConfigParser) Tree() map[string]any {
+	tree := make(map[string]any)
+	// Sorted sections add parents before their children.
+	for _, section := range p.Sections() {
+		node := tree
+		for _, name := range p.splitSection(section) {
+			child, ok := node[name].(map[string]any)
+			if !ok {
+				child = make(map[string]any)
+				node[name] = child
+			}
+			node = child
+		}
+		s, _ := p.section(section)
+		for option, value := range s.options {
+			if _, ok := node[option].(map[string]any); !ok {
+				node[option] = value
+			}
+		}
+	}
+
+	return tree
+}
+
+// splitSection splits the section name by the hierarchy separator.
+func (p *ConfigParser) splitSection(section string) []string {
+	if p.opt.hierarchySeparator == "" {
+		return []string{section}
+	}
+
+	return strings.Split(section, p.opt.hierarchySeparator)
+}
+
+// chainOptions returns a sorted list of option names of the sections,
+// excluding the extends key.
+func (p *ConfigParser) chainOptions(chain []*Section) []string {
+	seen := make(map[string]bool)
+	options := make([]string, 0)
+	for _, s := range chain {
+		for option := range s.options {
+			key := s.safeKey(option)
+			if !seen[key] && !p.isExtendsKey(option) {
+				seen[key] = true
+				options = append(options, option)
+			}
+		}
+	}
+	sort.Strings(options)
+
+	return options
+}
+
+// chainItems returns the options of the chain with their values, equivalent
+// options of the more specific sections take precedence.
+func (p *ConfigParser) chainItems(chain []*Section) Dict {
+	items := make(Dict)
+	seen := make(map[string]bool)
+	for _, s := range chain {
+		for k, v := range s.options {
+			key := s.safeKey(k)
+			if !seen[key] && !p.isExtendsKey(k) {
+				seen[key] = true
+				items[k] = v
+			}
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,117 @@
+package configparser_test
+
+import (
+	"strings"
+
+	"github.com/bigkevmcd/go-configparser"
+
+	gc "gopkg.in/check.v1"
+)
+
+const hierarchyConfig = `[DEFAULT]
+timeout = 30
+
+[server]
+host = example.com
+port = 80
+
+[server.eu]
+host = eu.example.com
+
+[server.eu.fra1]
+port = 8080
+url = http://%(host)s:%(port)s
+
+[server.us.nyc1]
+rack = 7
+`
+
+func parseHierarchy(c *gc.C) *configparser.ConfigParser {
+	p, err := configparser.ParseReaderWithOptions(
+		strings.NewReader(hierarchyConfig), configparser.HierarchicalSections("."),
+	)
+	c.Assert(err, gc.IsNil)
+
+	return p
+}
+
+// HierarchicalSections(sep) lookups should fall back to the parent sections
+// and then to the defaults.
+func (s *ConfigParserSuite) TestHierarchicalSectionsGet(c *gc.C) {
+	p := parseHierarchy(c)
+
+	v, err := p.Get("server.eu.fra1", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "eu.example.com")
+	v, err = p.Get("server.us.nyc1", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "example.com")
+	n, err := p.GetInt64("server.eu.fra1", "timeout")
+	c.Assert(err, gc.IsNil)
+	c.Assert(n, gc.Equals, int64(30))
+	v, err = p.GetInterpolated("server.eu.fra1", "url")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "http://eu.example.com:8080")
+	_, err = p.Get("server.eu.fra1", "rack")
+	c.Assert(err, gc.ErrorMatches, "no option \"rack\" in section: \"server.eu.fra1\"")
+	origin, err := p.Origin("server.eu.fra1", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(origin.Line, gc.Equals, 9)
+
+	items, err := p.ItemsWithDefaults("server.eu")
+	c.Assert(err, gc.IsNil)
+	c.Assert(items, gc.DeepEquals, configparser.Dict{
+		"host": "eu.example.com", "port": "80", "timeout": "30",
+	})
+	options, err := p.Options("server.us.nyc1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(options, gc.DeepEquals, []string{"host", "port", "rack", "timeout"})
+}
+
+// Without HierarchicalSections(sep) dotted sections should be independent.
+func (s *ConfigParserSuite) TestHierarchicalSectionsDisabled(c *gc.C) {
+	p := mustParse(c, hierarchyConfig)
+
+	_, err := p.Get("server.eu.fra1", "host")
+	c.Assert(err, gc.ErrorMatches, "no option \"host\" in section: \"server.eu.fra1\"")
+	children, err := p.Children("server")
+	c.Assert(err, gc.IsNil)
+	c.Assert(children, gc.HasLen, 0)
+}
+
+// Children(section) should return the sections with the nearest existing
+// parent being the named section.
+func (s *ConfigParserSuite) TestChildren(c *gc.C) {
+	p := parseHierarchy(c)
+
+	children, err := p.Children("server")
+	c.Assert(err, gc.IsNil)
+	c.Assert(children, gc.DeepEquals, []string{"server.eu", "server.us.nyc1"})
+	children, err = p.Children("server.eu")
+	c.Assert(err, gc.IsNil)
+	c.Assert(children, gc.DeepEquals, []string{"server.eu.fra1"})
+	_, err = p.Children("server.us")
+	c.Assert(err, gc.ErrorMatches, "no section: \"server.us\"")
+}
+
+// Tree() should return the sections as nested maps.
+func (s *ConfigParserSuite) TestTree(c *gc.C) {
+	p := parseHierarchy(c)
+
+	c.Assert(p.Tree(), gc.DeepEquals, map[string]any{
+		"server": map[string]any{
+			"host": "example.com",
+			"port": "80",
+			"eu": map[string]any{
+				"host": "eu.example.com",
+				"fra1": map[string]any{
+					"port": "8080",
+					"url":  "http://%(host)s:%(port)s",
+				},
+			},
+			"us": map[string]any{
+				"nyc1": map[string]any{"rack": "7"},
+			},
+		},
+	})
+}
@@ -0,0 +1,234 @@
+package configparser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// httpCacheSection stores the validators of the cached response.
+const httpCacheSection = "http"
+
+// defaultHTTPTimeout limits the requests of an HTTPSource without Timeout.
+const defaultHTTPTimeout = 30 * time.Second
+
+// HTTPSource is a Source which fetches the configuration over HTTP.
+//
+// Responses are validated with ETag and Last-Modified headers, so
+// unchanged configuration is not fetched again. The last fetched
+// configuration is used if the server can't be reached.
+type HTTPSource struct {
+	// URL of the configuration.
+	URL string
+	// Client is used for the requests, http.DefaultClient if nil.
+	Client *http.Client
+	// Timeout limits each request, 30 seconds if zero. Client.Timeout is
+	// still applied when set.
+	Timeout time.Duration
+	// CacheFile keeps the last fetched configuration on disk for offline
+	// start-up, validators are kept in CacheFile + ".meta". Disabled if empty.
+	CacheFile string
+	// CacheError is called by Load with the error when the fetched
+	// configuration can't be written to CacheFile, the configuration is
+	// still loaded.
+	CacheError func(error)
+
+	mu           sync.Mutex
+	body         []byte
+	etag         string
+	lastModified string
+}
+
+// Name returns the URL of the source.
+func (s *HTTPSource) Name() string { return s.URL }
+
+// Load fetches the configuration and parses it into the ConfigParser,
+// falling back to the last fetched configuration on errors.
+func (s *HTTPSource) Load(p *ConfigParser) error {
+	body, changed, err := s.fetch(context.Background())
+	if err != nil && body == nil {
+		return err
+	}
+	if err != nil && changed && s.CacheError != nil {
+		s.CacheError(err)
+	}
+
+	return p.ParseReader(bytes.NewReader(body))
+}
+
+// Watch fetches the configuration every interval until the context is done,
+// calling reload with a new ConfigParser created with given options each time
+// the configuration has changed, or with the error if it can't be fetched or
+// parsed. Failing to write CacheFile doesn't stop the reload, reload is then
+// called with both the new ConfigParser and the error, and the cache is
+// written again with the next change.
+func (s *HTTPSource) Watch(ctx context.Context, interval time.Duration, reload func(*ConfigParser, error), opts ...optFunc) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		body, changed, err := s.fetch(ctx)
+		if err != nil && !changed {
+			if ctx.Err() != nil {
+				return
+			}
+			reload(nil, err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+		// The changed configuration is returned with an error only if it
+		// can't be cached.
+		cacheErr := err
+
+		p := NewWithOptions(opts...)
+		p.source = s.Name()
+		err = p.ParseReader(bytes.NewReader(body))
+		p.source = ""
+		if err != nil {
+			reload(nil, err)
+			continue
+		}
+		reload(p, cacheErr)
+	}
+}
+
+// fetch returns the current configuration and whether it has changed since
+// the last fetch. The last fetched configuration is returned with the error
+// if the request fails, the changed configuration is returned with the error
+// if it can't be cached.
+func (s *HTTPSource) fetch(ctx context.Context) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.body == nil {
+		s.readCache()
+	}
+
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = defaultHTTPTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return s.body, false, err
+	}
+	if s.body != nil {
+		if s.etag != "" {
+			req.Header.Set("If-None-Match", s.etag)
+		}
+		if s.lastModified != "" {
+			req.Header.Set("If-Modified-Since", s.lastModified)
+		}
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return s.body, false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		if s.body != nil {
+			return s.body, false, nil
+		}
+	case http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return s.body, false, err
+		}
+		changed := !bytes.Equal(body, s.body)
+		s.body, s.etag, s.lastModified = body, resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if err := s.writeCache(); err != nil {
+			return s.body, changed, err
+		}
+		return s.body, changed, nil
+	}
+
+	return s.body, false, fmt.Errorf("unexpected response status %q", resp.Status)
+}
+
+// readCache loads the cached configuration, if there is a readable one.
+func (s *HTTPSource) readCache() {
+	if s.CacheFile == "" {
+		return
+	}
+	body, err := os.ReadFile(s.CacheFile)
+	if err != nil {
+		return
+	}
+	s.body = body
+
+	meta, err := Parse(s.CacheFile + ".meta")
+	if err != nil {
+		return
+	}
+	s.etag, _ = meta.Get(httpCacheSection, "etag")
+	s.lastModified, _ = meta.Get(httpCacheSection, "last-modified")
+}
+
+// writeCache stores the fetched configuration and its validators.
+//
+// The configuration is written first, so the validators never describe
+// a configuration which wasn't cached.
+func (s *HTTPSource) writeCache() error {
+	if s.CacheFile == "" {
+		return nil
+	}
+	if err := writeFileAtomic(s.CacheFile, s.body); err != nil {
+		return err
+	}
+
+	meta := New()
+	// Section is new and values are trimmed strings, so there can't be errors.
+	_ = meta.AddSection(httpCacheSection)
+	_ = meta.Set(httpCacheSection, "etag", s.etag)
+	_ = meta.Set(httpCacheSection, "last-modified", s.lastModified)
+	var buf bytes.Buffer
+	if err := meta.WriteWithDelimiter(&buf, "="); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(s.CacheFile+".meta", buf.Bytes())
+}
+
+// writeFileAtomic replaces the named file with the data, so readers never
+// see a partially written file.
+func writeFileAtomic(filename string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), filename)
+}
@@ -0,0 +1,219 @@
+package configparser_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/bigkevmcd/go-configparser"
+
+	gc "gopkg.in/check.v1"
+)
+
+// configServer serves the configuration with an ETag.
+type configServer struct {
+	mu          sync.Mutex
+	config      string
+	etag        string
+	down        bool
+	requests    int
+	notModified int
+}
+
+func (s *configServer) set(config, etag string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config, s.etag = config, etag
+}
+
+func (s *configServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	if s.down {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Header.Get("If-None-Match") == s.etag {
+		s.notModified++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", s.etag)
+	_, _ = w.Write([]byte(s.config))
+}
+
+// HTTPSource should load the configuration and revalidate it with the ETag.
+func (s *ConfigParserSuite) TestHTTPSource(c *gc.C) {
+	cs := &configServer{config: "[db]\nhost = localhost\n", etag: `"v1"`}
+	server := httptest.NewServer(cs)
+	defer server.Close()
+
+	src := &configparser.HTTPSource{URL: server.URL}
+	p, err := configparser.Load(src)
+	c.Assert(err, gc.IsNil)
+	v, err := p.Get("db", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "localhost")
+	origin, err := p.Origin("db", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(origin, gc.Equals, configparser.Origin{Source: server.URL, Line: 2})
+
+	_, err = configparser.Load(src)
+	c.Assert(err, gc.IsNil)
+	c.Assert(cs.requests, gc.Equals, 2)
+	c.Assert(cs.notModified, gc.Equals, 1)
+}
+
+// HTTPSource should fall back to the cache file if the server is unavailable.
+func (s *ConfigParserSuite) TestHTTPSourceOfflineFallback(c *gc.C) {
+	cs := &configServer{config: "[db]\nhost = localhost\n", etag: `"v1"`}
+	server := httptest.NewServer(cs)
+	defer server.Close()
+	cache := filepath.Join(c.MkDir(), "app.cfg")
+
+	_, err := configparser.Load(&configparser.HTTPSource{URL: server.URL, CacheFile: cache})
+	c.Assert(err, gc.IsNil)
+
+	// New source revalidates the cached copy.
+	_, err = configparser.Load(&configparser.HTTPSource{URL: server.URL, CacheFile: cache})
+	c.Assert(err, gc.IsNil)
+	c.Assert(cs.notModified, gc.Equals, 1)
+
+	cs.down = true
+	p, err := configparser.Load(&configparser.HTTPSource{URL: server.URL, CacheFile: cache})
+	c.Assert(err, gc.IsNil)
+	v, err := p.Get("db", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "localhost")
+
+	_, err = configparser.Load(&configparser.HTTPSource{URL: server.URL})
+	c.Assert(err, gc.ErrorMatches, `.*unexpected response status "503 Service Unavailable"`)
+}
+
+// HTTPSource should not keep the validators of a configuration which
+// couldn't be cached.
+func (s *ConfigParserSuite) TestHTTPSourceCacheWriteFailure(c *gc.C) {
+	cs := &configServer{config: "[db]\nhost = localhost\n", etag: `"v1"`}
+	server := httptest.NewServer(cs)
+	defer server.Close()
+	// The cache file can't replace a directory.
+	cache := c.MkDir()
+
+	var cacheErr error
+	_, err := configparser.Load(&configparser.HTTPSource{
+		URL:        server.URL,
+		CacheFile:  cache,
+		CacheError: func(err error) { cacheErr = err },
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(cacheErr, gc.NotNil)
+	_, err = os.Stat(cache + ".meta")
+	c.Assert(os.IsNotExist(err), gc.Equals, true)
+}
+
+// HTTPSource.Watch should call reload when the configuration changes.
+func (s *ConfigParserSuite) TestHTTPSourceWatch(c *gc.C) {
+	cs := &configServer{config: "[db]\nhost = localhost\n", etag: `"v1"`}
+	server := httptest.NewServer(cs)
+	defer server.Close()
+
+	src := &configparser.HTTPSource{URL: server.URL}
+	_, err := configparser.Load(src)
+	c.Assert(err, gc.IsNil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan *configparser.ConfigParser)
+	go src.Watch(ctx, time.Millisecond, func(p *configparser.ConfigParser, err error) {
+		c.Check(err, gc.IsNil)
+		reloaded <- p
+	})
+
+	cs.set("[db]\nhost = db.example.com\n", `"v2"`)
+	select {
+	case p := <-reloaded:
+		v, err := p.Get("db", "host")
+		c.Assert(err, gc.IsNil)
+		c.Assert(v, gc.Equals, "db.example.com")
+	case <-time.After(5 * time.Second):
+		c.Fatal("configuration was not reloaded")
+	}
+}
+
+// HTTPSource should give up on a hanging server after Timeout and fall back
+// to the cache file.
+func (s *ConfigParserSuite) TestHTTPSourceTimeout(c *gc.C) {
+	cs := &configServer{config: "[db]\nhost = localhost\n", etag: `"v1"`}
+	hang := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-hang:
+		default:
+			cs.ServeHTTP(w, r)
+			return
+		}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	cache := filepath.Join(c.MkDir(), "app.cfg")
+
+	_, err := configparser.Load(&configparser.HTTPSource{URL: server.URL, CacheFile: cache})
+	c.Assert(err, gc.IsNil)
+
+	close(hang)
+	p, err := configparser.Load(&configparser.HTTPSource{URL: server.URL, CacheFile: cache, Timeout: 10 * time.Millisecond})
+	c.Assert(err, gc.IsNil)
+	v, err := p.Get("db", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "localhost")
+}
+
+// HTTPSource.Watch should reload once with the error when the changed
+// configuration can't be cached.
+func (s *ConfigParserSuite) TestHTTPSourceWatchCacheFailure(c *gc.C) {
+	cs := &configServer{config: "[db]\nhost = localhost\n", etag: `"v1"`}
+	server := httptest.NewServer(cs)
+	defer server.Close()
+
+	var cacheErrors []error
+	src := &configparser.HTTPSource{
+		URL:        server.URL,
+		CacheFile:  filepath.Join(c.MkDir(), "missing", "app.cfg"),
+		CacheError: func(err error) { cacheErrors = append(cacheErrors, err) },
+	}
+	_, err := configparser.Load(src)
+	c.Assert(err, gc.IsNil)
+	c.Assert(cacheErrors, gc.HasLen, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	type result struct {
+		p   *configparser.ConfigParser
+		err error
+	}
+	reloaded := make(chan result, 10)
+	go src.Watch(ctx, time.Millisecond, func(p *configparser.ConfigParser, err error) {
+		reloaded <- result{p, err}
+	})
+
+	cs.set("[db]\nhost = db.example.com\n", `"v2"`)
+	select {
+	case r := <-reloaded:
+		c.Assert(r.err, gc.NotNil)
+		v, err := r.p.Get("db", "host")
+		c.Assert(err, gc.IsNil)
+		c.Assert(v, gc.Equals, "db.example.com")
+	case <-time.After(5 * time.Second):
+		c.Fatal("configuration was not reloaded")
+	}
+	select {
+	case r := <-reloaded:
+		c.Fatalf("unexpected reload: %v, %v", r.p, r.err)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,103 @@
+package configparser
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// parseFile parses the named file, st describes the including input.
+//
+// Returns an error if the file is already being parsed or the maximum
+// include depth is exceeded.
+func (p *ConfigParser) parseFile(filename string, st parseState) error {
+	abs, err := p.opt.fsys.Abs(filename)
+	if err != nil {
+		return err
+	}
+	for i, included := range st.stack {
+		if included == abs {
+			cycle := append(append([]string(nil), st.stack[i:]...), abs)
+			return fmt.Errorf("include cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	file, err := p.opt.fsys.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return p.parse(file, parseState{
+		source: filename,
+		stack:  append(st.stack[:len(st.stack):len(st.stack)], abs),
+		depth:  st.depth,
+	})
+}
+
+// includeDirective checks if the line is an include directive and returns
+// the included path pattern.
+func (p *ConfigParser) includeDirective(line string) (string, bool) {
+	d := p.opt.includeDirective
+	if d == "" || !strings.HasPrefix(line, d) {
+		return "", false
+	}
+	pattern := line[len(d):]
+	if pattern == "" || !unicode.IsSpace(rune(pattern[0])) {
+		return "", false
+	}
+
+	return strings.TrimSpace(pattern), true
+}
+
+// include parses the files matching the pattern in lexical order.
+//
+// Relative patterns are resolved against the directory of the including
+// file, or the working directory if the input is not a file.
+func (p *ConfigParser) include(pattern string, st parseState) error {
+	if st.depth >= p.opt.maxIncludeDepth {
+		return fmt.Errorf("include %q: maximum include depth %d exceeded", pattern, p.opt.maxIncludeDepth)
+	}
+
+	pattern = p.opt.fsys.Resolve(st.source, pattern)
+
+	filenames := []string{pattern}
+	if strings.ContainsAny(pattern, `*?[`) {
+		var err error
+		// Glob returns the matches in lexical order.
+		filenames, err = p.opt.fsys.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("include %q: %w", pattern, err)
+		}
+	}
+
+	st.depth++
+	for _, filename := range filenames {
+		if err := p.checkIncludeRoot(filename); err != nil {
+			return err
+		}
+		if err := p.parseFile(filename, st); err != nil {
+			return fmt.Errorf("include %q: %w", filename, err)
+		}
+	}
+
+	return nil
+}
+
+// checkIncludeRoot returns an error if the file is outside of the
+// configured include root directory.
+func (p *ConfigParser) checkIncludeRoot(filename string) error {
+	if p.opt.includeRoot == "" {
+		return nil
+	}
+
+	ok, err := p.opt.fsys.Contains(p.opt.includeRoot, filename)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("include %q is outside of the root directory %q", filename, p.opt.includeRoot)
+	}
+
+	return nil
+}
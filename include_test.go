@@ -0,0 +1,107 @@
+package configparser_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/bigkevmcd/go-configparser"
+
+	gc "gopkg.in/check.v1"
+)
+
+// writeFiles creates the files with the given contents within dir.
+func writeFiles(c *gc.C, dir string, files map[string]string) {
+	for name, data := range files {
+		filename := filepath.Join(dir, name)
+		c.Assert(os.MkdirAll(filepath.Dir(filename), 0o755), gc.IsNil)
+		c.Assert(os.WriteFile(filename, []byte(data), 0o644), gc.IsNil)
+	}
+}
+
+// %include directive should parse the included files relative to the
+// including file in lexical order and record their origins.
+func (s *ConfigParserSuite) TestIncludeDirective(c *gc.C) {
+	dir := c.MkDir()
+	writeFiles(c, dir, map[string]string{
+		"app.cfg":         "[app]\nname = app\n%include conf/*.cfg\nport = 80\n",
+		"conf/10-db.cfg":  "[db]\nhost = localhost\n",
+		"conf/20-db.cfg":  "[db]\nhost = db.example.com\n",
+		"conf/ignore.txt": "invalid",
+	})
+
+	p, err := configparser.ParseWithOptions(filepath.Join(dir, "app.cfg"), configparser.AllowIncludes)
+	c.Assert(err, gc.IsNil)
+
+	v, err := p.Get("db", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "db.example.com")
+	// Including file continues in its own section.
+	v, err = p.Get("app", "port")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "80")
+
+	origin, err := p.Origin("db", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(origin, gc.Equals, configparser.Origin{Source: filepath.Join(dir, "conf/20-db.cfg"), Line: 2})
+}
+
+// [include] section should parse the listed files.
+func (s *ConfigParserSuite) TestIncludeSection(c *gc.C) {
+	dir := c.MkDir()
+	writeFiles(c, dir, map[string]string{
+		"app.cfg":  "[app]\nname = app\n\n[includes]\npaths = a.cfg\n  b.cfg\n",
+		"a.cfg":    "[a]\nkey = a\n",
+		"b.cfg":    "[b]\nkey = b\n",
+		"skip.cfg": "[skip]\nkey = skip\n",
+	})
+
+	p, err := configparser.ParseWithOptions(
+		filepath.Join(dir, "app.cfg"), configparser.IncludeSection("includes", "paths"),
+	)
+	c.Assert(err, gc.IsNil)
+	c.Assert(p.Sections(), gc.DeepEquals, []string{"a", "app", "b"})
+}
+
+// Includes should fail on cycles.
+func (s *ConfigParserSuite) TestIncludeCycle(c *gc.C) {
+	dir := c.MkDir()
+	writeFiles(c, dir, map[string]string{
+		"a.cfg": "[a]\n%include b.cfg\n",
+		"b.cfg": "[b]\n%include a.cfg\n",
+	})
+
+	_, err := configparser.ParseWithOptions(filepath.Join(dir, "a.cfg"), configparser.AllowIncludes)
+	c.Assert(err, gc.ErrorMatches, `include ".*b.cfg": include ".*a.cfg": include cycle: .*a.cfg -> .*b.cfg -> .*a.cfg`)
+}
+
+// Includes should fail if the maximum depth is exceeded.
+func (s *ConfigParserSuite) TestIncludeMaxDepth(c *gc.C) {
+	dir := c.MkDir()
+	writeFiles(c, dir, map[string]string{
+		"a.cfg": "[a]\n%include b.cfg\n",
+		"b.cfg": "[b]\n%include c.cfg\n",
+		"c.cfg": "[c]\n",
+	})
+
+	_, err := configparser.ParseWithOptions(
+		filepath.Join(dir, "a.cfg"), configparser.AllowIncludes, configparser.MaxIncludeDepth(1),
+	)
+	c.Assert(err, gc.ErrorMatches, `include ".*b.cfg": include "c.cfg": maximum include depth 1 exceeded`)
+}
+
+// Includes should fail for files outside of the root directory.
+func (s *ConfigParserSuite) TestIncludeRoot(c *gc.C) {
+	dir := c.MkDir()
+	writeFiles(c, dir, map[string]string{
+		"etc/app.cfg":   "[app]\n%include ../secret.cfg\n",
+		"etc/local.cfg": "[local]\n",
+		"secret.cfg":    "[secret]\n",
+	})
+
+	_, err := configparser.ParseWithOptions(
+		filepath.Join(dir, "etc/app.cfg"),
+		configparser.AllowIncludes,
+		configparser.IncludeRoot(filepath.Join(dir, "etc")),
+	)
+	c.Assert(err, gc.ErrorMatches, `include ".*secret.cfg" is outside of the root directory ".*etc"`)
+}
@@ -0,0 +1,141 @@
+package configparser
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// lineage returns the named section followed by the sections it inherits
+// from and its parent sections, see linearize. Each section is preceded by
+// its profile overlay.
+func (p *ConfigParser) lineage(section string) ([]*Section, error) {
+	names, err := p.linearize(section, nil)
+	if err != nil {
+		return nil, err
+	}
+	chain := make([]*Section, 0, len(names))
+	for _, name := range names {
+		if overlay := p.overlay(name); overlay != nil {
+			chain = append(chain, overlay)
+		}
+		chain = append(chain, p.config[p.sectionKey(name)])
+	}
+
+	return chain, nil
+}
+
+// linearize returns the named section followed by its bases, the inherited
+// sections from left to right and the parent section, in the C3 order used
+// for Python classes: every section precedes its bases, the bases keep
+// their order and a shared base follows all the sections inheriting from it.
+func (p *ConfigParser) linearize(section string, stack []string) ([]string, error) {
+	key := p.sectionKey(section)
+	for i, s := range stack {
+		if p.sectionKey(s) == key {
+			return nil, fmt.Errorf("inheritance cycle: %s", strings.Join(append(stack[i:], section), " -> "))
+		}
+	}
+	stack = append(stack, section)
+
+	bases := p.extends(p.config[key])
+	if parent := p.parent(section); parent != "" {
+		bases = append(bases, parent)
+	}
+	seqs := make([][]string, 0, len(bases)+1)
+	direct := make([]string, 0, len(bases))
+	for _, base := range bases {
+		// Defaults are always consulted last.
+		if p.isDefaultSection(base) {
+			continue
+		}
+		if !p.HasSection(base) {
+			return nil, fmt.Errorf("section %q extends missing section %q", section, base)
+		}
+		inherited, err := p.linearize(base, stack)
+		if err != nil {
+			return nil, err
+		}
+		seqs = append(seqs, inherited)
+		direct = append(direct, base)
+	}
+	seqs = append(seqs, direct)
+
+	merged, ok := p.mergeLinearizations(seqs)
+	if !ok {
+		return nil, fmt.Errorf("inconsistent inheritance order for section %q", section)
+	}
+
+	return append([]string{section}, merged...), nil
+}
+
+// mergeLinearizations merges the sequences by repeatedly taking the first
+// head, which is not in the tail of any sequence. Returns false if there is
+// no such head.
+func (p *ConfigParser) mergeLinearizations(seqs [][]string) ([]string, bool) {
+	var merged []string
+	for {
+		remaining := seqs[:0]
+		for _, seq := range seqs {
+			if len(seq) > 0 {
+				remaining = append(remaining, seq)
+			}
+		}
+		seqs = remaining
+		if len(seqs) == 0 {
+			return merged, true
+		}
+
+		var head string
+		found := false
+		for _, seq := range seqs {
+			if !p.inTails(seqs, seq[0]) {
+				head, found = seq[0], true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+		merged = append(merged, head)
+		for i, seq := range seqs {
+			if p.sectionKey(seq[0]) == p.sectionKey(head) {
+				seqs[i] = seq[1:]
+			}
+		}
+	}
+}
+
+// inTails returns true if the section is in the tail of any sequence.
+func (p *ConfigParser) inTails(seqs [][]string, section string) bool {
+	for _, seq := range seqs {
+		for _, s := range seq[1:] {
+			if p.sectionKey(s) == p.sectionKey(section) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// extends returns the names of the sections the section inherits from.
+func (p *ConfigParser) extends(s *Section) []string {
+	if p.opt.extendsKey == "" {
+		return nil
+	}
+	value, err := s.Get(p.opt.extendsKey)
+	if err != nil {
+		return nil
+	}
+
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// isExtendsKey returns true if the option is the extends key.
+func (p *ConfigParser) isExtendsKey(option string) bool {
+	return p.opt.extendsKey != "" &&
+		p.optionKey(option) == p.optionKey(p.opt.extendsKey)
+}
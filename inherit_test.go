@@ -0,0 +1,114 @@
+package configparser_test
+
+import (
+	"strings"
+
+	"github.com/bigkevmcd/go-configparser"
+
+	gc "gopkg.in/check.v1"
+)
+
+const inheritanceConfig = `[DEFAULT]
+timeout = 30
+
+[common]
+user = app
+pool = 5
+
+[tuned]
+__extends__ = common
+pool = 20
+
+[db-primary]
+__extends__ = common
+host = primary.example.com
+port = 5432
+
+[db-replica]
+__extends__ = db-primary, tuned
+host = replica.example.com
+`
+
+// Inheritance lookups should follow the extends key in the C3 order before
+// the defaults.
+func (s *ConfigParserSuite) TestInheritance(c *gc.C) {
+	p, err := configparser.ParseReaderWithOptions(
+		strings.NewReader(inheritanceConfig), configparser.Inheritance,
+	)
+	c.Assert(err, gc.IsNil)
+
+	v, err := p.Get("db-replica", "port")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "5432")
+	// common is consulted after tuned, which overrides it.
+	v, err = p.Get("db-replica", "pool")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "20")
+	v, err = p.Get("tuned", "pool")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "20")
+	_, err = p.Get("common", "__extends__")
+	c.Assert(err, gc.ErrorMatches, "no option \"__extends__\" in section: \"common\"")
+
+	items, err := p.ItemsWithDefaults("db-replica")
+	c.Assert(err, gc.IsNil)
+	c.Assert(items, gc.DeepEquals, configparser.Dict{
+		"host":    "replica.example.com",
+		"port":    "5432",
+		"user":    "app",
+		"pool":    "20",
+		"timeout": "30",
+	})
+	options, err := p.Options("db-primary")
+	c.Assert(err, gc.IsNil)
+	c.Assert(options, gc.DeepEquals, []string{"host", "pool", "port", "timeout", "user"})
+}
+
+// ExtendsKey(key) should report cycles, missing sections and inconsistent
+// orders.
+func (s *ConfigParserSuite) TestInheritanceErrors(c *gc.C) {
+	p, err := configparser.ParseReaderWithOptions(strings.NewReader(`[a]
+inherits = b
+
+[b]
+inherits = c
+
+[c]
+inherits = a
+
+[d]
+inherits = missing
+
+[e]
+
+[f]
+
+[g]
+inherits = e, f
+
+[h]
+inherits = f, e
+
+[i]
+inherits = g, h
+`), configparser.ExtendsKey("inherits"))
+	c.Assert(err, gc.IsNil)
+
+	_, err = p.Get("a", "option")
+	c.Assert(err, gc.ErrorMatches, "inheritance cycle: a -> b -> c -> a")
+	_, err = p.ItemsWithDefaults("d")
+	c.Assert(err, gc.ErrorMatches, "section \"d\" extends missing section \"missing\"")
+	_, err = p.Get("i", "option")
+	c.Assert(err, gc.ErrorMatches, "inconsistent inheritance order for section \"i\"")
+}
+
+// Without Inheritance the extends key should be a plain option.
+func (s *ConfigParserSuite) TestInheritanceDisabled(c *gc.C) {
+	p := mustParse(c, inheritanceConfig)
+
+	_, err := p.Get("db-replica", "port")
+	c.Assert(err, gc.ErrorMatches, "no option \"port\" in section: \"db-replica\"")
+	v, err := p.Get("db-replica", "__extends__")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "db-primary, tuned")
+}
@@ -0,0 +1,172 @@
+package configparser
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// sectionInstances returns all occurrences of the named section.
+func (p *ConfigParser) sectionInstances(name string) []*Section {
+	if instances, present := p.instances[p.sectionKey(name)]; present {
+		return instances
+	}
+	if s, present := p.config[p.sectionKey(name)]; present {
+		return []*Section{s}
+	}
+
+	return nil
+}
+
+// SectionInstances returns all occurrences of the named section in order,
+// which are kept separately with DuplicateSectionList. Otherwise the only
+// instance is the section itself.
+//
+// Returns an error if the section does not exist.
+func (p *ConfigParser) SectionInstances(name string) ([]*Section, error) {
+	if !p.HasSection(name) {
+		return nil, getNoSectionError(name)
+	}
+
+	return append([]*Section(nil), p.sectionInstances(name)...), nil
+}
+
+// UnmarshalInstances stores the instances of the named section, see
+// SectionInstances, in the slice pointed to by v, which elements are structs
+// or pointers to structs.
+//
+// Exported fields are set from the options named by the "ini" field tag or
+// the field name, compared using the option name transform, falling back
+// to the defaults.
+// Fields tagged with "-" and fields without options are left unchanged.
+// Values are converted with the converters to strings, integers, floats and
+// booleans, interpolation is not applied.
+//
+// Returns an error if the section does not exist.
+// Returns an error if a value can't be converted to the field type.
+func (p *ConfigParser) UnmarshalInstances(name string, v any) error {
+	slice := reflect.ValueOf(v)
+	if slice.Kind() != reflect.Pointer || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("unmarshal %q: expected pointer to slice, got %T", name, v)
+	}
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if elemType.Kind() == reflect.Pointer {
+		structType = elemType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("unmarshal %q: expected slice of structs, got %T", name, v)
+	}
+
+	instances, err := p.SectionInstances(name)
+	if err != nil {
+		return err
+	}
+
+	result := reflect.MakeSlice(slice.Type(), 0, len(instances))
+	for i, s := range instances {
+		elem := reflect.New(structType)
+		if err := p.unmarshalSection(s, elem.Elem()); err != nil {
+			return fmt.Errorf("unmarshal %q instance %d: %w", name, i, err)
+		}
+		if elemType.Kind() != reflect.Pointer {
+			elem = elem.Elem()
+		}
+		result = reflect.Append(result, elem)
+	}
+	slice.Set(result)
+
+	return nil
+}
+
+// unmarshalSection sets the fields of the struct from the options
+// of the section.
+func (p *ConfigParser) unmarshalSection(s *Section, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		option := field.Name
+		if tag, ok := field.Tag.Lookup("ini"); ok {
+			if tag == "-" {
+				continue
+			}
+			option = tag
+		}
+
+		value, err := s.Get(option)
+		if err != nil {
+			if value, err = p.defaults.Get(option); err != nil {
+				continue
+			}
+		}
+		if err := p.setField(v.Field(i), value); err != nil {
+			return fmt.Errorf("option %q: %w", option, err)
+		}
+	}
+
+	return nil
+}
+
+// setField converts the value to the type of the field and sets it.
+func (p *ConfigParser) setField(f reflect.Value, value string) error {
+	switch f.Kind() {
+	case reflect.String:
+		converted, err := p.opt.converters[StringConv](value)
+		if err != nil {
+			return err
+		}
+		s, err := assertValue[string](converted)
+		if err != nil {
+			return err
+		}
+		f.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		converted, err := p.opt.converters[IntConv](value)
+		if err != nil {
+			return err
+		}
+		n, err := assertValue[int64](converted)
+		if err != nil {
+			return err
+		}
+		if f.OverflowInt(n) {
+			return fmt.Errorf("value %d overflows %s", n, f.Type())
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(strings.TrimSpace(value), 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		converted, err := p.opt.converters[FloatConv](value)
+		if err != nil {
+			return err
+		}
+		n, err := assertValue[float64](converted)
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	case reflect.Bool:
+		converted, err := p.opt.converters[BoolConv](value)
+		if err != nil {
+			return err
+		}
+		b, err := assertValue[bool](converted)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field type %s", f.Type())
+	}
+
+	return nil
+}
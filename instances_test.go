@@ -0,0 +1,124 @@
+package configparser_test
+
+import (
+	"strings"
+
+	"github.com/bigkevmcd/go-configparser"
+
+	gc "gopkg.in/check.v1"
+)
+
+const instancesConfig = `[DEFAULT]
+weight = 1
+
+[server]
+host = a.example.com
+port = 80
+
+[pool]
+name = main
+
+[server]
+host = b.example.com
+port = 8080
+weight = 3
+`
+
+type server struct {
+	Host     string
+	Port     uint16 `ini:"port"`
+	Weight   int
+	Backup   bool
+	Internal string `ini:"-"`
+}
+
+func parseInstances(policy configparser.DuplicateSectionPolicy) (*configparser.ConfigParser, error) {
+	return configparser.ParseReaderWithOptions(
+		strings.NewReader(instancesConfig), configparser.DuplicateSections(policy),
+	)
+}
+
+// DuplicateSections(DuplicateSectionList) should keep each occurrence of
+// the repeated sections as a separate instance.
+func (s *ConfigParserSuite) TestSectionInstances(c *gc.C) {
+	p, err := parseInstances(configparser.DuplicateSectionList)
+	c.Assert(err, gc.IsNil)
+
+	instances, err := p.SectionInstances("server")
+	c.Assert(err, gc.IsNil)
+	c.Assert(instances, gc.HasLen, 2)
+	c.Assert(instances[0].Items(), gc.DeepEquals, configparser.Dict{"host": "a.example.com", "port": "80"})
+	c.Assert(instances[1].Items(), gc.DeepEquals, configparser.Dict{
+		"host": "b.example.com", "port": "8080", "weight": "3",
+	})
+	// Lookups use the first instance.
+	v, err := p.Get("server", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "a.example.com")
+
+	instances, err = p.SectionInstances("pool")
+	c.Assert(err, gc.IsNil)
+	c.Assert(instances, gc.HasLen, 1)
+	_, err = p.SectionInstances("unknown")
+	c.Assert(err, gc.ErrorMatches, `no section: "unknown"`)
+
+	var buf strings.Builder
+	c.Assert(p.WriteWithDelimiter(&buf, "="), gc.IsNil)
+	c.Assert(buf.String(), gc.Equals, `[DEFAULT]
+weight = 1
+
+[pool]
+name = main
+
+[server]
+host = a.example.com
+port = 80
+
+[server]
+host = b.example.com
+port = 8080
+weight = 3
+
+`)
+}
+
+// DuplicateSections(policy) should merge the repeated sections by default
+// or report them as errors.
+func (s *ConfigParserSuite) TestDuplicateSections(c *gc.C) {
+	p, err := parseInstances(configparser.DuplicateSectionMerge)
+	c.Assert(err, gc.IsNil)
+	instances, err := p.SectionInstances("server")
+	c.Assert(err, gc.IsNil)
+	c.Assert(instances, gc.HasLen, 1)
+	c.Assert(instances[0].Items(), gc.DeepEquals, configparser.Dict{
+		"host": "b.example.com", "port": "8080", "weight": "3",
+	})
+
+	_, err = parseInstances(configparser.DuplicateSectionError)
+	c.Assert(err, gc.ErrorMatches, `section "server" already exists: 11`)
+}
+
+// UnmarshalInstances(name, v) should store the instances in a slice
+// of structs.
+func (s *ConfigParserSuite) TestUnmarshalInstances(c *gc.C) {
+	p, err := parseInstances(configparser.DuplicateSectionList)
+	c.Assert(err, gc.IsNil)
+
+	var servers []server
+	c.Assert(p.UnmarshalInstances("server", &servers), gc.IsNil)
+	c.Assert(servers, gc.DeepEquals, []server{
+		{Host: "a.example.com", Port: 80, Weight: 1},
+		{Host: "b.example.com", Port: 8080, Weight: 3},
+	})
+
+	var pointers []*server
+	c.Assert(p.UnmarshalInstances("server", &pointers), gc.IsNil)
+	c.Assert(pointers, gc.HasLen, 2)
+	c.Assert(pointers[1].Host, gc.Equals, "b.example.com")
+
+	assertSuccessful(c, p.Set("server", "backup", "maybe"))
+	err = p.UnmarshalInstances("server", &servers)
+	c.Assert(err, gc.ErrorMatches, `unmarshal "server" instance 0: option "Backup": not a boolean: "maybe"`)
+	err = p.UnmarshalInstances("server", servers)
+	c.Assert(err, gc.ErrorMatches, `unmarshal "server": expected pointer to slice, got .*`)
+}
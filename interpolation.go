@@ -21,12 +21,11 @@ func (p *ConfigParser) getInterpolated(section, option string, c Interpolator) (
 // All % interpolations are expanded in the return values, based on
 // the defaults passed into the constructor and the DEFAULT section.
 func (p *ConfigParser) GetInterpolated(section, option string) (string, error) {
-	o, err := p.Items(section)
+	dicts, err := p.interpolationDicts(section)
 	if err != nil {
 		return "", err
 	}
-	p.opt.interpolation.Add(chainmap.Dict(p.Defaults()), chainmap.Dict(o))
-	return p.getInterpolated(section, option, p.opt.interpolation)
+	return p.getInterpolated(section, option, p.newInterpolator(dicts...))
 }
 
 // GetInterpolatedWithVars returns a string value for the named option.
@@ -36,12 +35,55 @@ func (p *ConfigParser) GetInterpolated(section, option string) (string, error) {
 // provided using the 'v' argument, which must be a Dict whose contents contents
 // override any pre-existing defaults.
 func (p *ConfigParser) GetInterpolatedWithVars(section, option string, v Dict) (string, error) {
-	o, err := p.Items(section)
+	dicts, err := p.interpolationDicts(section)
 	if err != nil {
 		return "", err
 	}
-	p.opt.interpolation.Add(chainmap.Dict(p.Defaults()), chainmap.Dict(o), chainmap.Dict(v))
-	return p.getInterpolated(section, option, p.opt.interpolation)
+	vars := make(chainmap.Dict, len(v))
+	for k, value := range v {
+		vars[p.optionKey(k)] = value
+	}
+	return p.getInterpolated(section, option, p.newInterpolator(append(dicts, vars)...))
+}
+
+// newInterpolator returns the interpolator for a single lookup with
+// the dicts added. The default chainmap is created per lookup, so the options
+// of the sections read earlier don't leak into the lookup. Interpolators set
+// with Interpolation, including chainmaps, get the dicts added on each lookup.
+func (p *ConfigParser) newInterpolator(dicts ...chainmap.Dict) Interpolator {
+	if !p.opt.customInterpolation {
+		return chainmap.New(dicts...)
+	}
+	p.opt.interpolation.Add(dicts...)
+
+	return p.opt.interpolation
+}
+
+// interpolationDicts returns copies of the options of the sections consulted
+// by the lookups of the named section, the defaults first, keyed by
+// the lookup keys of the options.
+func (p *ConfigParser) interpolationDicts(section string) ([]chainmap.Dict, error) {
+	chain, err := p.chain(section)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.chainDicts(chain), nil
+}
+
+// chainDicts returns copies of the options of the chain in reverse order,
+// see interpolationDicts.
+func (p *ConfigParser) chainDicts(chain []*Section) []chainmap.Dict {
+	dicts := make([]chainmap.Dict, 0, len(chain))
+	for i := len(chain) - 1; i >= 0; i-- {
+		dict := make(chainmap.Dict, len(chain[i].options))
+		for k, v := range chain[i].options {
+			dict[chain[i].safeKey(k)] = v
+		}
+		dicts = append(dicts, dict)
+	}
+
+	return dicts
 }
 
 // Private method which does the work of interpolating a value
@@ -53,7 +95,7 @@ func (p *ConfigParser) interpolate(value string, options Interpolator) string {
 			value = interpolater.ReplaceAllStringFunc(value, func(m string) string {
 				// No ReplaceAllStringSubMatchFunc so apply the regexp twice
 				match := interpolater.FindAllStringSubmatch(m, 1)[0][1]
-				replacement := options.Get(match)
+				replacement := options.Get(p.optionKey(match))
 				return replacement
 			})
 		}
@@ -63,17 +105,19 @@ func (p *ConfigParser) interpolate(value string, options Interpolator) string {
 
 // ItemsWithDefaultsInterpolated returns a copy of the dict for the section.
 func (p *ConfigParser) ItemsWithDefaultsInterpolated(section string) (Dict, error) {
-	s, err := p.ItemsWithDefaults(section)
+	if !p.HasSection(section) {
+		return nil, getNoSectionError(section)
+	}
+	// The chain is resolved once for all the options.
+	chain, err := p.chain(section)
 	if err != nil {
 		return nil, err
 	}
-	// TOOD: Optimise this...instantiate the ChainMap and delegate to interpolate()
-	for k := range s {
-		v, err := p.GetInterpolated(section, k)
-		if err != nil {
-			return nil, err
-		}
-		s[k] = v
+	items := p.chainItems(chain)
+	interpolator := p.newInterpolator(p.chainDicts(chain)...)
+	for k, v := range items {
+		items[k] = p.interpolate(v, interpolator)
 	}
-	return s, nil
+
+	return items, nil
 }
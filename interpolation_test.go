@@ -48,3 +48,23 @@ func (s *ConfigParserSuite) TestItemsWithDefaultsInterpolated(c *C) {
 		"base_dir":        "/srv",
 	})
 }
+
+// GetInterpolated(section, option) should not depend on the sections
+// interpolated before, nor on the vars passed before.
+func (s *ConfigParserSuite) TestGetInterpolatedIndependentOfReadOrder(c *C) {
+	p := mustParse(c, "[a]\nsecret = hunter2\n\n[b]\nref = %(secret)s\n")
+
+	result, err := p.GetInterpolated("a", "secret")
+	c.Assert(err, IsNil)
+	c.Assert(result, Equals, "hunter2")
+	result, err = p.GetInterpolated("b", "ref")
+	c.Assert(err, IsNil)
+	c.Assert(result, Equals, "")
+
+	result, err = p.GetInterpolatedWithVars("b", "ref", configparser.Dict{"secret": "var"})
+	c.Assert(err, IsNil)
+	c.Assert(result, Equals, "var")
+	result, err = p.GetInterpolated("b", "ref")
+	c.Assert(err, IsNil)
+	c.Assert(result, Equals, "")
+}
@@ -0,0 +1,145 @@
+package configparser
+
+import "fmt"
+
+// mergeSource is the origin source of merged values without a known origin.
+const mergeSource = "merge"
+
+// MergeStrategy resolves the value of an option defined with different
+// values in both merged configurations.
+type MergeStrategy func(section, option, existing, incoming string) (string, error)
+
+// MergeConflictError is returned by MergeError for conflicting options.
+type MergeConflictError struct {
+	Section  string
+	Option   string
+	Existing string
+	Incoming string
+}
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf(
+		"conflicting values for option %q in section %q: %q and %q",
+		e.Option, e.Section, e.Existing, e.Incoming,
+	)
+}
+
+// MergeOverride is a MergeStrategy which replaces existing values.
+func MergeOverride(_, _, _, incoming string) (string, error) {
+	return incoming, nil
+}
+
+// MergeKeepExisting is a MergeStrategy which keeps existing values.
+func MergeKeepExisting(_, _, existing, _ string) (string, error) {
+	return existing, nil
+}
+
+// MergeError is a MergeStrategy which fails with a MergeConflictError.
+func MergeError(section, option, existing, incoming string) (string, error) {
+	return "", &MergeConflictError{
+		Section:  section,
+		Option:   option,
+		Existing: existing,
+		Incoming: incoming,
+	}
+}
+
+// Merge adds the sections and options of other to the ConfigParser,
+// resolving the options present in both with the strategy. Defaults of
+// other are merged into the defaults of the ConfigParser.
+//
+// Options with the values collected from the repeated keys, see
+// DuplicateCollect, are resolved with all values joined with newlines and
+// keep all values of the chosen configuration. Repeated instances of
+// the sections of other, see DuplicateSectionList, are added after
+// the instances of the ConfigParser.
+//
+// Merged options keep the origin recorded by other. The merge is applied
+// atomically, see Update.
+func (p *ConfigParser) Merge(other *ConfigParser, strategy MergeStrategy) error {
+	return p.Update(func(tx *Tx) error {
+		if err := tx.mergeSection(p.opt.defaultSection, other.defaults, strategy); err != nil {
+			return err
+		}
+
+		for _, name := range other.sectionNames() {
+			if !tx.HasSection(name) {
+				if err := tx.AddSection(name); err != nil {
+					return err
+				}
+			}
+			from, _ := other.section(name)
+			if err := tx.mergeSection(name, from, strategy); err != nil {
+				return err
+			}
+			tx.mergeInstances(name, other.sectionInstances(name)[1:])
+		}
+
+		return nil
+	})
+}
+
+// mergeSection merges options of the given section into the staged section
+// or into its overlay, the same section Set changes, see Profile.
+func (tx *Tx) mergeSection(name string, from *Section, strategy MergeStrategy) error {
+	section, err := tx.staged.section(name)
+	if err != nil {
+		return err
+	}
+	to := section
+	if overlay := tx.staged.overlay(name); overlay != nil {
+		to = overlay
+	}
+
+	for _, option := range from.Options() {
+		incoming, _ := from.joinedValue(option)
+		value := incoming
+
+		// Options missing from the overlay fall back to the section.
+		existing, present := to.joinedValue(option)
+		if !present {
+			existing, present = section.joinedValue(option)
+		}
+		if present {
+			if existing == incoming {
+				continue
+			}
+			var err error
+			if value, err = strategy(name, option, existing, incoming); err != nil {
+				return err
+			}
+			if value == existing {
+				continue
+			}
+		}
+
+		if err := tx.Set(name, option, value); err != nil {
+			return err
+		}
+
+		values := []string{value}
+		origin, present := from.origin(option)
+		if value == incoming {
+			values, _ = from.getAll(option)
+		}
+		if !present || value != incoming {
+			origin = Origin{Source: mergeSource}
+		}
+		to.setAllWithOrigin(option, values, origin)
+	}
+
+	return nil
+}
+
+// mergeInstances adds copies of the repeated instances of the section
+// to the staged instances.
+func (tx *Tx) mergeInstances(name string, instances []*Section) {
+	if len(instances) == 0 {
+		return
+	}
+	staged := tx.staged.sectionInstances(name)
+	for _, s := range instances {
+		staged = append(staged, s.clone())
+	}
+	tx.staged.instances[tx.staged.sectionKey(name)] = staged
+}
@@ -0,0 +1,334 @@
+package configparser
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+)
+
+// Version is the value of an option in one of the merged configurations.
+// Present is false if the option is missing in that configuration. Values
+// holds the values collected from the repeated keys, see DuplicateCollect,
+// if there are more than one, Value then holds them joined with newlines.
+type Version struct {
+	Value   string
+	Present bool
+	Values  []string
+}
+
+// values returns all values of the version.
+func (v Version) values() []string {
+	if v.Values == nil {
+		return []string{v.Value}
+	}
+
+	return v.Values
+}
+
+// Conflict describes an option changed differently by both sides of
+// a three-way merge.
+type Conflict struct {
+	Section string
+	Option  string
+	Base    Version
+	Ours    Version
+	Theirs  Version
+}
+
+// Merge3Result holds the result of a three-way merge.
+type Merge3Result struct {
+	// Merged contains the merged configuration, conflicting options keep
+	// the value of ours.
+	Merged    *ConfigParser
+	Conflicts []Conflict
+}
+
+// HasConflicts returns true if the merge has conflicting options.
+func (r *Merge3Result) HasConflicts() bool {
+	return len(r.Conflicts) > 0
+}
+
+// Merge3 applies the changes made between base and theirs to ours.
+//
+// Changes are merged per option: an option changed on one side only takes
+// the changed value, an option changed on both sides to different values
+// is reported as a Conflict. A section removed by theirs is removed if
+// no options of ours remain in it. Values collected from the repeated keys,
+// see DuplicateCollect, are merged together as a single value, reported
+// joined with newlines and one by one in Version.Values.
+//
+// Returns an error if any configuration has repeated section instances,
+// see DuplicateSectionList.
+func Merge3(base, ours, theirs *ConfigParser) (*Merge3Result, error) {
+	for _, p := range []*ConfigParser{base, ours, theirs} {
+		if err := p.checkNoInstances(); err != nil {
+			return nil, err
+		}
+	}
+
+	r := &Merge3Result{Merged: ours.clone(), Conflicts: make([]Conflict, 0)}
+	defaultSection := ours.opt.defaultSection
+
+	// Sections are matched by their lookup keys and keep the names of ours.
+	seen := make(map[string]bool)
+	sections := make([]string, 0)
+	for _, p := range []*ConfigParser{ours, base, theirs} {
+		for _, s := range p.sectionNames() {
+			if !seen[ours.sectionKey(s)] {
+				seen[ours.sectionKey(s)] = true
+				sections = append(sections, s)
+			}
+		}
+	}
+	sort.Strings(sections)
+	sections = append([]string{defaultSection}, sections...)
+
+	for _, section := range sections {
+		conflicts, err := r.mergeSection(section, base, ours, theirs)
+		if err != nil {
+			return nil, err
+		}
+		r.Conflicts = append(r.Conflicts, conflicts...)
+
+		// Drop the section removed by theirs if nothing was left by ours.
+		if section != defaultSection && base.HasSection(section) && !theirs.HasSection(section) &&
+			r.Merged.HasSection(section) && len(conflicts) == 0 &&
+			len(r.Merged.config[r.Merged.sectionKey(section)].Options()) == 0 {
+			if err := r.Merged.RemoveSection(section); err != nil {
+				return nil, err
+			}
+		}
+		// Add the section added by theirs, even if it has no options.
+		if section != defaultSection && !base.HasSection(section) && theirs.HasSection(section) &&
+			!r.Merged.HasSection(section) {
+			if err := r.Merged.AddSection(section); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// mergeSection merges options of the section and returns the conflicts.
+// Options are matched by their lookup keys and keep the names of ours.
+func (r *Merge3Result) mergeSection(section string, base, ours, theirs *ConfigParser) ([]Conflict, error) {
+	b, o, t := merge3Items(base, section), merge3Items(ours, section), merge3Items(theirs, section)
+
+	options := make(valueLists)
+	for _, items := range []valueLists{t, b, o} {
+		for k, v := range items {
+			options[k] = optionValues{name: v.name}
+		}
+	}
+
+	conflicts := make([]Conflict, 0)
+	for _, key := range options.keys() {
+		option := options[key].name
+		switch {
+		case sameValues(o, t, key), sameValues(t, b, key):
+			// Both sides agree or theirs has not changed, keep ours.
+		case sameValues(o, b, key):
+			if v, present := t[key]; present {
+				option = v.name
+			}
+			if err := r.takeTheirs(section, option, merge3Version(t, key), theirs); err != nil {
+				return nil, err
+			}
+		default:
+			conflicts = append(conflicts, Conflict{
+				Section: section, Option: option,
+				Base:   merge3Version(b, key),
+				Ours:   merge3Version(o, key),
+				Theirs: merge3Version(t, key),
+			})
+		}
+	}
+
+	return conflicts, nil
+}
+
+// takeTheirs applies the version of theirs to the merged configuration.
+func (r *Merge3Result) takeTheirs(section, option string, v Version, theirs *ConfigParser) error {
+	if !v.Present {
+		return r.Merged.RemoveOption(section, option)
+	}
+
+	if !r.Merged.isDefaultSection(section) && !r.Merged.HasSection(section) {
+		if err := r.Merged.AddSection(section); err != nil {
+			return err
+		}
+	}
+	to, err := r.Merged.section(section)
+	if err != nil {
+		return err
+	}
+	from, err := theirs.section(section)
+	if err != nil {
+		return err
+	}
+	origin, _ := from.origin(option)
+	values, _ := from.getAll(option)
+	to.setAllWithOrigin(option, values, origin)
+
+	return nil
+}
+
+// checkNoInstances returns an error if any section has repeated instances.
+func (p *ConfigParser) checkNoInstances() error {
+	for _, name := range p.sectionNames() {
+		if len(p.sectionInstances(name)) > 1 {
+			return fmt.Errorf("section %q has repeated instances, which can't be merged", name)
+		}
+	}
+
+	return nil
+}
+
+func merge3Items(p *ConfigParser, section string) valueLists {
+	if section == p.opt.defaultSection || p.HasSection(section) {
+		// Section was checked, so there can't be an error.
+		items, _ := p.valueLists(section)
+		return items
+	}
+
+	return valueLists{}
+}
+
+func merge3Version(items valueLists, key string) Version {
+	_, present := items[key]
+
+	return Version{Value: items.joined(key), Present: present, Values: items.collected(key)}
+}
+
+// sameValues returns true if the option has the same values in both,
+// or is missing in both.
+func sameValues(a, b valueLists, key string) bool {
+	va, inA := a[key]
+	vb, inB := b[key]
+
+	return inA == inB && slices.Equal(va.values, vb.values)
+}
+
+// SaveWithConflictMarkers writes the merged configuration to the named
+// file with the specified delimiter, see WriteWithConflictMarkers.
+func (r *Merge3Result) SaveWithConflictMarkers(filename, delimiter string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return r.WriteWithConflictMarkers(f, delimiter)
+}
+
+// WriteWithConflictMarkers writes the merged configuration to w with the
+// specified delimiter. Conflicting options are written between
+// "<<<<<<< ours", "=======" and ">>>>>>> theirs" marker lines.
+func (r *Merge3Result) WriteWithConflictMarkers(w io.Writer, delimiter string) error {
+	if len(r.Conflicts) == 0 {
+		return r.Merged.WriteWithDelimiter(w, delimiter)
+	}
+
+	conflicts := make(map[string][]Conflict)
+	sections := []string{r.Merged.opt.defaultSection}
+	seen := map[string]bool{r.Merged.opt.defaultSection: true}
+	for _, c := range r.Conflicts {
+		conflicts[c.Section] = append(conflicts[c.Section], c)
+		if !seen[c.Section] {
+			seen[c.Section] = true
+			sections = append(sections, c.Section)
+		}
+	}
+	for _, s := range r.Merged.sectionNames() {
+		if !seen[s] {
+			seen[s] = true
+			sections = append(sections, s)
+		}
+	}
+	sort.Strings(sections[1:])
+	// Unnamed section sorts first and goes before DEFAULT.
+	if len(sections) > 1 && r.Merged.isUnnamedSection(sections[1]) {
+		sections[0], sections[1] = sections[1], sections[0]
+	}
+
+	for _, name := range sections {
+		section, err := r.Merged.section(name)
+		if err != nil {
+			// Section exists only in the conflicts.
+			section = r.Merged.newSection(name)
+		}
+		if len(section.Options()) == 0 && len(conflicts[name]) == 0 &&
+			(r.Merged.isDefaultSection(name) || r.Merged.isUnnamedSection(name)) {
+			continue
+		}
+		if err := r.Merged.writeConflictSection(w, delimiter, section, conflicts[name]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeConflictSection writes the section like writeSection, followed by
+// the conflicting options between the conflict markers. Conflicting values
+// of ours keep their inline comments.
+func (p *ConfigParser) writeConflictSection(w io.Writer, delimiter string, section *Section, conflicts []Conflict) error {
+	var err error
+	// Unnamed section is written without the header.
+	if section.Name != UnnamedSection {
+		_, err = fmt.Fprintf(w, "[%s]\n", section.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	conflicting := make(map[string]bool)
+	for _, c := range conflicts {
+		conflicting[section.safeKey(c.Option)] = true
+	}
+	for _, option := range section.Options() {
+		if conflicting[section.safeKey(option)] {
+			continue
+		}
+		values, _ := section.getAll(option)
+		for i, value := range values {
+			if err := writeOption(w, delimiter, option, p.formatValue(value, section.comment(option, i))); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, c := range conflicts {
+		if _, err := io.WriteString(w, "<<<<<<< ours\n"); err != nil {
+			return err
+		}
+		// Collected values are written one line per value.
+		if c.Ours.Present {
+			for i, value := range c.Ours.values() {
+				value = p.formatValue(value, section.comment(c.Option, i))
+				if err := writeOption(w, delimiter, c.Option, value); err != nil {
+					return err
+				}
+			}
+		}
+		if _, err := io.WriteString(w, "=======\n"); err != nil {
+			return err
+		}
+		if c.Theirs.Present {
+			for _, value := range c.Theirs.values() {
+				if err := writeOption(w, delimiter, c.Option, p.formatValue(value, "")); err != nil {
+					return err
+				}
+			}
+		}
+		if _, err := io.WriteString(w, ">>>>>>> theirs\n"); err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
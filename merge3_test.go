@@ -0,0 +1,238 @@
+package configparser_test
+
+import (
+	"strings"
+
+	"github.com/bigkevmcd/go-configparser"
+
+	gc "gopkg.in/check.v1"
+)
+
+const merge3Base = `[server]
+host = localhost
+port = 80
+timeout = 30
+
+[legacy]
+enabled = no
+`
+
+// Merge3(base, ours, theirs) should apply non-conflicting changes from
+// both sides.
+func (s *ConfigParserSuite) TestMerge3(c *gc.C) {
+	base := mustParse(c, merge3Base)
+	ours := mustParse(c, "[server]\nhost = example.com\nport = 80\ntimeout = 30\n\n[legacy]\nenabled = no\n\n[local]\nkey = value\n")
+	theirs := mustParse(c, "[server]\nhost = localhost\nport = 8080\nworkers = 4\n")
+
+	r, err := configparser.Merge3(base, ours, theirs)
+	c.Assert(err, gc.IsNil)
+	c.Assert(r.HasConflicts(), gc.Equals, false)
+
+	c.Assert(r.Merged.Sections(), gc.DeepEquals, []string{"local", "server"})
+	items, err := r.Merged.Items("server")
+	c.Assert(err, gc.IsNil)
+	c.Assert(items, gc.DeepEquals, configparser.Dict{
+		"host":    "example.com",
+		"port":    "8080",
+		"workers": "4",
+	})
+	// Inputs are not modified.
+	c.Assert(ours.Sections(), gc.DeepEquals, []string{"legacy", "local", "server"})
+}
+
+// Merge3(base, ours, theirs) should match the options by their
+// case-insensitive names.
+func (s *ConfigParserSuite) TestMerge3OptionCase(c *gc.C) {
+	base := mustParse(c, "[s]\nHost = 1\nPort = 80\n")
+	ours := mustParse(c, "[s]\nHost = 3\nPort = 80\n")
+	theirs := mustParse(c, "[s]\nhost = 2\nport = 8080\n")
+
+	r, err := configparser.Merge3(base, ours, theirs)
+	c.Assert(err, gc.IsNil)
+	c.Assert(r.Conflicts, gc.DeepEquals, []configparser.Conflict{{
+		Section: "s",
+		Option:  "Host",
+		Base:    configparser.Version{Value: "1", Present: true},
+		Ours:    configparser.Version{Value: "3", Present: true},
+		Theirs:  configparser.Version{Value: "2", Present: true},
+	}})
+	items, err := r.Merged.Items("s")
+	c.Assert(err, gc.IsNil)
+	c.Assert(items, gc.DeepEquals, configparser.Dict{"Host": "3", "Port": "8080"})
+}
+
+// Merge3(base, ours, theirs) should match the sections by their lookup keys.
+func (s *ConfigParserSuite) TestMerge3SectionTransform(c *gc.C) {
+	parse := func(data string) *configparser.ConfigParser {
+		p, err := configparser.ParseReaderWithOptions(
+			strings.NewReader(data), configparser.SectionTransform(strings.ToLower),
+		)
+		c.Assert(err, gc.IsNil)
+
+		return p
+	}
+	base := parse("[server]\nhost = a\nport = 80\n")
+	ours := parse("[Server]\nhost = b\nport = 80\n")
+	theirs := parse("[SERVER]\nhost = a\nport = 8080\n")
+
+	r, err := configparser.Merge3(base, ours, theirs)
+	c.Assert(err, gc.IsNil)
+	c.Assert(r.HasConflicts(), gc.Equals, false)
+	c.Assert(r.Merged.Sections(), gc.DeepEquals, []string{"Server"})
+	items, err := r.Merged.Items("server")
+	c.Assert(err, gc.IsNil)
+	c.Assert(items, gc.DeepEquals, configparser.Dict{"host": "b", "port": "8080"})
+}
+
+// Merge3(base, ours, theirs) should add the sections added by theirs,
+// even without options.
+func (s *ConfigParserSuite) TestMerge3EmptySection(c *gc.C) {
+	base := mustParse(c, "[s]\nkey = 1\n")
+	theirs := mustParse(c, "[s]\nkey = 1\n\n[new]\n")
+
+	r, err := configparser.Merge3(base, base, theirs)
+	c.Assert(err, gc.IsNil)
+	c.Assert(r.Merged.Sections(), gc.DeepEquals, []string{"new", "s"})
+}
+
+// Merge3(base, ours, theirs) should report options changed on both sides
+// and write them with conflict markers.
+func (s *ConfigParserSuite) TestMerge3Conflicts(c *gc.C) {
+	base := mustParse(c, merge3Base)
+	ours := mustParse(c, "[server]\nhost = localhost\nport = 81\n\n[legacy]\nenabled = yes\n")
+	theirs := mustParse(c, "[server]\nhost = localhost\nport = 8080\ntimeout = 60\n")
+
+	r, err := configparser.Merge3(base, ours, theirs)
+	c.Assert(err, gc.IsNil)
+	c.Assert(r.Conflicts, gc.DeepEquals, []configparser.Conflict{
+		{
+			Section: "legacy",
+			Option:  "enabled",
+			Base:    configparser.Version{Value: "no", Present: true},
+			Ours:    configparser.Version{Value: "yes", Present: true},
+			Theirs:  configparser.Version{},
+		},
+		{
+			Section: "server",
+			Option:  "port",
+			Base:    configparser.Version{Value: "80", Present: true},
+			Ours:    configparser.Version{Value: "81", Present: true},
+			Theirs:  configparser.Version{Value: "8080", Present: true},
+		},
+		{
+			Section: "server",
+			Option:  "timeout",
+			Base:    configparser.Version{Value: "30", Present: true},
+			Ours:    configparser.Version{},
+			Theirs:  configparser.Version{Value: "60", Present: true},
+		},
+	})
+
+	var b strings.Builder
+	c.Assert(r.WriteWithConflictMarkers(&b, "="), gc.IsNil)
+	c.Assert(b.String(), gc.Equals, `[legacy]
+<<<<<<< ours
+enabled = yes
+=======
+>>>>>>> theirs
+
+[server]
+host = localhost
+<<<<<<< ours
+port = 81
+=======
+port = 8080
+>>>>>>> theirs
+<<<<<<< ours
+=======
+timeout = 60
+>>>>>>> theirs
+
+`)
+}
+
+// WriteWithConflictMarkers(w, delimiter) should escape the values and keep
+// the inline comments, so the resolved file parses back.
+func (s *ConfigParserSuite) TestMerge3ConflictMarkersRoundTrip(c *gc.C) {
+	parse := func(data string) *configparser.ConfigParser {
+		p, err := configparser.ParseReaderWithOptions(
+			strings.NewReader(data), configparser.InlineCommentPrefixes(configparser.Prefixes{"#"}),
+		)
+		c.Assert(err, gc.IsNil)
+
+		return p
+	}
+	base := parse("[s]\nm = 1 \\# not comment\nport = 80\n")
+	ours := parse("[s]\nm = 1 \\# not comment # note\nport = 81 # ours\n")
+	theirs := parse("[s]\nm = 1 \\# not comment\nport = 8080 \\# x\n")
+
+	r, err := configparser.Merge3(base, ours, theirs)
+	c.Assert(err, gc.IsNil)
+	var b strings.Builder
+	c.Assert(r.WriteWithConflictMarkers(&b, "="), gc.IsNil)
+	c.Assert(b.String(), gc.Equals, `[s]
+m = 1 \# not comment # note
+<<<<<<< ours
+port = 81 # ours
+=======
+port = 8080 \# x
+>>>>>>> theirs
+
+`)
+
+	// Resolve the conflict keeping ours.
+	resolved := strings.Replace(b.String(), "<<<<<<< ours\n", "", 1)
+	resolved = strings.Replace(resolved, "=======\nport = 8080 \\# x\n>>>>>>> theirs\n", "", 1)
+	p := parse(resolved)
+	v, err := p.Get("s", "m")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "1 # not comment")
+	v, err = p.Get("s", "port")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "81")
+	comment, err := p.Comment("s", "port")
+	c.Assert(err, gc.IsNil)
+	c.Assert(comment, gc.Equals, "# ours")
+}
+
+// Merge3(base, ours, theirs) should merge the collected values together and
+// reject the repeated section instances.
+func (s *ConfigParserSuite) TestMerge3CollectedValuesAndInstances(c *gc.C) {
+	collect := configparser.DuplicateKeys(configparser.DuplicateCollect)
+	base, err := configparser.ParseReaderWithOptions(strings.NewReader("[server]\nallow = a\n"), collect)
+	c.Assert(err, gc.IsNil)
+	ours, err := configparser.ParseReaderWithOptions(strings.NewReader("[server]\nallow = a\nport = 80\n"), collect)
+	c.Assert(err, gc.IsNil)
+	theirs, err := configparser.ParseReaderWithOptions(strings.NewReader("[server]\nallow = a\nallow = b\n"), collect)
+	c.Assert(err, gc.IsNil)
+
+	r, err := configparser.Merge3(base, ours, theirs)
+	c.Assert(err, gc.IsNil)
+	c.Assert(r.HasConflicts(), gc.Equals, false)
+	values, err := r.Merged.GetAll("server", "allow")
+	c.Assert(err, gc.IsNil)
+	c.Assert(values, gc.DeepEquals, []string{"a", "b"})
+
+	// Collected values differ from a multiline value of the same lines.
+	ours, err = configparser.ParseReaderWithOptions(strings.NewReader("[server]\nallow = a\n\tb\n"), collect)
+	c.Assert(err, gc.IsNil)
+	r, err = configparser.Merge3(base, ours, theirs)
+	c.Assert(err, gc.IsNil)
+	c.Assert(r.Conflicts, gc.DeepEquals, []configparser.Conflict{{
+		Section: "server", Option: "allow",
+		Base:   configparser.Version{Value: "a", Present: true},
+		Ours:   configparser.Version{Value: "a\nb", Present: true},
+		Theirs: configparser.Version{Value: "a\nb", Present: true, Values: []string{"a", "b"}},
+	}})
+	var buf strings.Builder
+	c.Assert(r.WriteWithConflictMarkers(&buf, "="), gc.IsNil)
+	c.Assert(buf.String(), gc.Equals, "[server]\n<<<<<<< ours\nallow = a\n\tb\n=======\nallow = a\nallow = b\n>>>>>>> theirs\n\n")
+
+	theirs, err = configparser.ParseReaderWithOptions(
+		strings.NewReader("[server]\nallow = a\n\n[server]\nallow = b\n"),
+		configparser.DuplicateSections(configparser.DuplicateSectionList),
+	)
+	c.Assert(err, gc.IsNil)
+	_, err = configparser.Merge3(base, ours, theirs)
+	c.Assert(err, gc.ErrorMatches, `section "server" has repeated instances, which can't be merged`)
+}
@@ -0,0 +1,122 @@
+package configparser_test
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/bigkevmcd/go-configparser"
+
+	gc "gopkg.in/check.v1"
+)
+
+const (
+	vendorConfig = "[DEFAULT]\nlog_level = info\n\n[db]\nhost = localhost\nport = 5432\n"
+	siteConfig   = "[DEFAULT]\nlog_level = debug\n\n[db]\nhost = db.example.com\n\n[cache]\nsize = 10\n"
+)
+
+// Merge(other, MergeOverride) should replace existing values.
+func (s *ConfigParserSuite) TestMergeOverride(c *gc.C) {
+	p := mustParse(c, vendorConfig)
+
+	err := p.Merge(mustParse(c, siteConfig), configparser.MergeOverride)
+	c.Assert(err, gc.IsNil)
+
+	c.Assert(p.Sections(), gc.DeepEquals, []string{"cache", "db"})
+	c.Assert(p.Defaults(), gc.DeepEquals, configparser.Dict{"log_level": "debug"})
+	items, err := p.Items("db")
+	c.Assert(err, gc.IsNil)
+	c.Assert(items, gc.DeepEquals, configparser.Dict{"host": "db.example.com", "port": "5432"})
+	items, err = p.Items("cache")
+	c.Assert(err, gc.IsNil)
+	c.Assert(items, gc.DeepEquals, configparser.Dict{"size": "10"})
+}
+
+// Merge(other, MergeKeepExisting) should only add missing options.
+func (s *ConfigParserSuite) TestMergeKeepExisting(c *gc.C) {
+	p := mustParse(c, vendorConfig)
+
+	err := p.Merge(mustParse(c, siteConfig), configparser.MergeKeepExisting)
+	c.Assert(err, gc.IsNil)
+
+	c.Assert(p.Defaults(), gc.DeepEquals, configparser.Dict{"log_level": "info"})
+	v, err := p.Get("db", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "localhost")
+	v, err = p.Get("cache", "size")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "10")
+}
+
+// Merge(other, MergeError) should fail on conflicts without modifying the
+// ConfigParser.
+func (s *ConfigParserSuite) TestMergeError(c *gc.C) {
+	p := mustParse(c, vendorConfig)
+
+	err := p.Merge(mustParse(c, siteConfig), configparser.MergeError)
+	c.Assert(err, gc.ErrorMatches, `conflicting values for option "log_level" in section "DEFAULT": "info" and "debug"`)
+
+	var conflict *configparser.MergeConflictError
+	c.Assert(errors.As(err, &conflict), gc.Equals, true)
+	c.Assert(conflict.Option, gc.Equals, "log_level")
+	c.Assert(p.Sections(), gc.DeepEquals, []string{"db"})
+}
+
+// Merge(other, strategy) should use the custom resolver and record the
+// origin of merged options.
+func (s *ConfigParserSuite) TestMergeCustomStrategy(c *gc.C) {
+	p := mustParse(c, vendorConfig)
+	other, err := configparser.Parse("testdata/example.cfg")
+	c.Assert(err, gc.IsNil)
+	assertSuccessful(c, other.AddSection("db"))
+	assertSuccessful(c, other.Set("db", "port", "5433"))
+
+	err = p.Merge(other, func(section, option, existing, incoming string) (string, error) {
+		return existing + "," + incoming, nil
+	})
+	c.Assert(err, gc.IsNil)
+
+	v, err := p.Get("db", "port")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "5432,5433")
+
+	origin, err := p.Origin("db", "port")
+	c.Assert(err, gc.IsNil)
+	c.Assert(origin, gc.Equals, configparser.Origin{Source: "merge"})
+	origin, err = p.Origin("follower", "max_build_time")
+	c.Assert(err, gc.IsNil)
+	c.Assert(origin, gc.Equals, configparser.Origin{Source: "testdata/example.cfg", Line: 13})
+}
+
+// Merge(other, strategy) should keep the collected values and the repeated
+// section instances of other.
+func (s *ConfigParserSuite) TestMergeCollectedValuesAndInstances(c *gc.C) {
+	p := mustParse(c, "[server]\nhost = a.example.com\n")
+	other, err := configparser.ParseReaderWithOptions(strings.NewReader(`[server]
+host = b.example.com
+allow = 10.0.0.1
+allow = 10.0.0.2
+
+[server]
+host = c.example.com
+`),
+		configparser.DuplicateKeys(configparser.DuplicateCollect),
+		configparser.DuplicateSections(configparser.DuplicateSectionList),
+	)
+	c.Assert(err, gc.IsNil)
+
+	err = p.Merge(other, configparser.MergeOverride)
+	c.Assert(err, gc.IsNil)
+
+	values, err := p.GetAll("server", "allow")
+	c.Assert(err, gc.IsNil)
+	c.Assert(values, gc.DeepEquals, []string{"10.0.0.1", "10.0.0.2"})
+	instances, err := p.SectionInstances("server")
+	c.Assert(err, gc.IsNil)
+	c.Assert(instances, gc.HasLen, 2)
+	c.Assert(instances[0].Items(), gc.DeepEquals, configparser.Dict{"host": "b.example.com", "allow": "10.0.0.2"})
+	c.Assert(instances[1].Items(), gc.DeepEquals, configparser.Dict{"host": "c.example.com"})
+
+	// Collected values are resolved together.
+	err = p.Merge(mustParse(c, "[server]\nallow = 10.0.0.3\n"), configparser.MergeError)
+	c.Assert(err, gc.ErrorMatches, `conflicting values for option "allow" in section "server": "10.0.0.1\\n10.0.0.2" and "10.0.0.3"`)
+}
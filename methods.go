@@ -8,7 +8,54 @@ import (
 )
 
 func (p *ConfigParser) isDefaultSection(section string) bool {
-	return section == p.opt.defaultSection
+	return p.sectionKey(section) == p.sectionKey(p.opt.defaultSection)
+}
+
+// sectionKey returns the lookup key of the section name, see
+// SectionTransform.
+func (p *ConfigParser) sectionKey(section string) string {
+	return p.opt.sectionTransform(section)
+}
+
+// optionKey returns the lookup key of the option name, see OptionTransform.
+func (p *ConfigParser) optionKey(option string) string {
+	return p.opt.optionTransform(strings.TrimSpace(option))
+}
+
+// validateSectionName checks the section name with the validator,
+// see SectionNameValidator.
+func (p *ConfigParser) validateSectionName(section string) error {
+	if p.opt.sectionValidator == nil {
+		return nil
+	}
+	if err := p.opt.sectionValidator(section); err != nil {
+		return fmt.Errorf("invalid section name %q: %w", section, err)
+	}
+
+	return nil
+}
+
+// newSection returns a new section using the option name transform.
+func (p *ConfigParser) newSection(name string) *Section {
+	s := newSection(name)
+	s.transform = p.opt.optionTransform
+
+	return s
+}
+
+// section returns the named section, including DEFAULT.
+//
+// Returns an error if the section does not exist.
+func (p *ConfigParser) section(name string) (*Section, error) {
+	if p.isDefaultSection(name) {
+		return p.defaults, nil
+	}
+	s, present := p.config[p.sectionKey(name)]
+	if !present {
+		return nil, getNoSectionError(name)
+	}
+
+	return s, nil
 }
 
 // Defaults returns the items in the map used for default values.
@@ -16,12 +63,30 @@ func (p *ConfigParser) Defaults() Dict {
 	return p.defaults.Items()
 }
 
-// Sections returns a list of section names, excluding [DEFAULT].
+// Sections returns a list of section names, excluding [DEFAULT],
+// the profile overlays of the existing sections and the unnamed section,
+// unless ListUnnamedSection is set.
 // Returned slice is sorted.
 func (p *ConfigParser) Sections() []string {
 	sections := make([]string, 0, len(p.config))
-	for section := range p.config {
-		sections = append(sections, section)
+	for _, section := range p.sectionNames() {
+		if p.isUnnamedSection(section) && !p.opt.listUnnamedSection {
+			continue
+		}
+		if !p.isOverlay(section) {
+			sections = append(sections, section)
+		}
+	}
+
+	return sections
+}
+
+// sectionNames returns a sorted list of all section names, excluding
+// [DEFAULT].
+func (p *ConfigParser) sectionNames() []string {
+	sections := make([]string, 0, len(p.config))
+	for _, s := range p.config {
+		sections = append(sections, s.Name)
 	}
 	sort.Strings(sections)
 
@@ -33,15 +98,26 @@ func (p *ConfigParser) Sections() []string {
 // Returns an error if a section by the specified name
 // already exists.
 // Returns an error if the specified name DEFAULT or any of its
-// case-insensitive variants.
+// variants equivalent with the section name transform.
+// Returns an error if the specified name is UnnamedSection and
+// AllowUnnamedSection is not set.
+// Returns an error if the section name validator rejects the name.
 // Returns nil if no error and the section is created
 func (p *ConfigParser) AddSection(section string) error {
+	if p.inTx {
+		return ErrInTransaction
+	}
 	if p.isDefaultSection(section) {
 		return fmt.Errorf("invalid section name: %q", section)
+	} else if p.isUnnamedSection(section) && !p.opt.allowUnnamedSection {
+		return fmt.Errorf("invalid section name: %q", section)
+	} else if err := p.validateSectionName(section); err != nil {
+		return err
 	} else if p.HasSection(section) {
 		return fmt.Errorf("section %q already exists", section)
 	}
-	p.config[section] = newSection(section)
+	p.config[p.sectionKey(section)] = p.newSection(section)
+	p.notify(Change{Kind: SectionAdded, Section: section})
 
 	return nil
 }
@@ -51,12 +127,13 @@ func (p *ConfigParser) AddSection(section string) error {
 //
 // The DEFAULT section is not acknowledged.
 func (p *ConfigParser) HasSection(section string) bool {
-	_, present := p.config[section]
+	_, present := p.config[p.sectionKey(section)]
 
 	return present
 }
 
-// Options returns a list of option names for the given section name.
+// Options returns a list of option names for the given section name,
+// including the defaults, the parent and the inherited sections.
 // Returned slice is sorted.
 //
 // Returns an error if the section does not exist.
@@ -64,20 +141,12 @@ func (p *ConfigParser) Options(section string) ([]string, error) {
 	if !p.HasSection(section) {
 		return nil, getNoSectionError(section)
 	}
-	seenOptions := make(map[string]bool)
-	for _, option := range p.config[section].Options() {
-		seenOptions[option] = true
-	}
-	for _, option := range p.defaults.Options() {
-		seenOptions[option] = true
-	}
-	options := make([]string, 0)
-	for option := range seenOptions {
-		options = append(options, option)
+	chain, err := p.chain(section)
+	if err != nil {
+		return nil, err
 	}
-	sort.Strings(options)
 
-	return options, nil
+	return p.chainOptions(chain), nil
 }
 
 // Get returns string value for the named option.
@@ -100,34 +169,55 @@ func (p *ConfigParser) Get(section, option string) (string, error) {
 }
 
 func (p *ConfigParser) get(section, option string) (string, error) {
-	// Special case.
-	if p.isDefaultSection(section) {
-		return p.defaults.Get(option)
+	chain, err := p.chain(section)
+	if err != nil {
+		return "", err
 	}
 
-	s, ok := p.config[section]
-	if !ok {
-		return "", getNoSectionError(section)
+	// If given section has no option, fallback to check the inherited
+	// and the parent sections and the defaults.
+	own := p.ownSections(section)
+	for i, s := range chain {
+		// The extends key is not inherited.
+		if i >= own && p.isExtendsKey(option) {
+			break
+		}
+		if v, err := s.Get(option); err == nil {
+			return v, nil
+		}
 	}
 
-	v, err := s.Get(option)
-	if err == nil {
-		return v, nil
+	return "", getNoOptionError(section, option)
+}
+
+// GetAll returns all values of the named option in order, collected from
+// the repeated keys, see DuplicateCollect.
+//
+// Returns an error if a section does not exist.
+// Returns an error if the option does not exist either in the section or in
+// the defaults.
+func (p *ConfigParser) GetAll(section, option string) ([]string, error) {
+	chain, err := p.chain(section)
+	if err != nil {
+		return nil, err
 	}
 
-	// If given section has no option, fallback to check default.
-	dv, derr := p.defaults.Get(option)
-	if derr != nil {
-		// If option is not present in default section, return
-		// original error with the requested section name.
-		return "", err
+	own := p.ownSections(section)
+	for i, s := range chain {
+		if i >= own && p.isExtendsKey(option) {
+			break
+		}
+		if values, ok := s.getAll(option); ok {
+			return values, nil
+		}
 	}
 
-	return dv, nil
+	return nil, getNoOptionError(section, option)
 }
 
 // ItemsWithDefaults returns a copy of the named section Dict including
-// any values from the Defaults.
+// any values from the Defaults, the parent and the inherited sections,
+// excluding the extends key.
 //
 // NOTE: This is different from the Python version which returns a list of
 // tuples
@@ -135,49 +225,60 @@ func (p *ConfigParser) ItemsWithDefaults(section string) (Dict, error) {
 	if !p.HasSection(section) {
 		return nil, getNoSectionError(section)
 	}
-	s := make(Dict)
-
-	for k, v := range p.defaults.Items() {
-		s[k] = v
-	}
-	for k, v := range p.config[section].Items() {
-		s[k] = v
+	chain, err := p.chain(section)
+	if err != nil {
+		return nil, err
 	}
 
-	return s, nil
+	return p.chainItems(chain), nil
 }
 
 // Items returns a copy of the section Dict not including the Defaults.
+// Options of the overlay take precedence, if a profile is active and
+// the section has one, see Profile.
 //
 // NOTE: This is different from the Python version which returns a list of
 // tuples.
 func (p *ConfigParser) Items(section string) (Dict, error) {
-	if section == p.opt.defaultSection {
-		return p.defaults.Items(), nil
-	}
-
-	if !p.HasSection(section) {
+	if !p.isDefaultSection(section) && !p.HasSection(section) {
 		return nil, getNoSectionError(section)
 	}
 
-	return p.config[section].Items(), nil
+	s, _ := p.section(section)
+
+	return p.overlayItems(section, s), nil
 }
 
-// Set puts the given option into the named section.
+// Set puts the given option into the named section or into its overlay, if
+// a profile is active and the section has one, see Profile. The inline
+// comment of the replaced value is dropped.
 //
 // Returns an error if the section does not exist.
 func (p *ConfigParser) Set(section, option, value string) error {
-	var setSection *Section
+	if p.inTx {
+		return ErrInTransaction
+	}
+	setSection, err := p.section(section)
+	if err != nil {
+		return err
+	}
+	// Changes of the profile view go to the overlay, which is looked up first.
+	if overlay := p.overlay(section); overlay != nil {
+		setSection, section = overlay, overlay.Name
+	}
 
-	if p.isDefaultSection(section) {
-		setSection = p.defaults
-	} else if _, present := p.config[section]; !present {
-		return getNoSectionError(section)
-	} else {
-		setSection = p.config[section]
+	change := Change{Kind: OptionAdded, Section: section, Option: option}
+	if old, err := setSection.Get(option); err == nil {
+		change.Kind, change.OldValue = OptionChanged, old
 	}
+	// Options set while loading a Source originate from it.
+	if err := setSection.addWithOrigin(option, value, Origin{Source: p.source}); err != nil {
+		return err
+	}
+	change.NewValue, _ = setSection.Get(option)
+	p.notify(change)
 
-	return setSection.Add(option, value)
+	return nil
 }
 
 // GetInt64 returns int64 representation of the named option.
@@ -239,74 +340,60 @@ func (p *ConfigParser) GetBool(section, option string) (bool, error) {
 
 // RemoveSection removes given section from the ConfigParser.
 func (p *ConfigParser) RemoveSection(section string) error {
+	if p.inTx {
+		return ErrInTransaction
+	}
 	if !p.HasSection(section) {
 		return getNoSectionError(section)
 	}
-	delete(p.config, section)
+	delete(p.config, p.sectionKey(section))
+	delete(p.instances, p.sectionKey(section))
+	p.notify(Change{Kind: SectionRemoved, Section: section})
 
 	return nil
 }
 
-// HasOption checks if section contains option.
+// HasOption checks if section or its overlay, if a profile is active,
+// contains option.
 func (p *ConfigParser) HasOption(section, option string) (bool, error) {
-	var s *Section
-	if p.isDefaultSection(section) {
-		s = p.defaults
-	} else if _, present := p.config[section]; !present {
-		return false, getNoSectionError(section)
-	} else {
-		s = p.config[section]
+	s, err := p.section(section)
+	if err != nil {
+		return false, err
+	}
+	if overlay := p.overlay(section); overlay != nil {
+		if _, err := overlay.Get(option); err == nil {
+			return true, nil
+		}
 	}
-	_, err := s.Get(option)
+	_, err = s.Get(option)
 
 	return err == nil, nil
 }
 
-// RemoveOption removes option from the section.
+// RemoveOption removes option from the section or from its overlay, if
+// a profile is active and the overlay contains the option, see Profile.
 func (p *ConfigParser) RemoveOption(section, option string) error {
-	var s *Section
-	if p.isDefaultSection(section) {
-		s = p.defaults
-	} else if _, present := p.config[section]; !present {
-		return getNoSectionError(section)
-	} else {
-		s = p.config[section]
+	if p.inTx {
+		return ErrInTransaction
 	}
-
-	return s.Remove(option)
-}
-
-func (p *ConfigParser) inOptions(key string) error {
-	opts, err := p.allOptions()
+	s, err := p.section(section)
 	if err != nil {
 		return err
 	}
-
-	for _, o := range opts {
-		if key == o {
-			return fmt.Errorf(
-				"option %q already exists and strict flag was set",
-				key,
-			)
+	// Changes of the profile view go to the overlay, which is looked up first.
+	if overlay := p.overlay(section); overlay != nil {
+		if _, err := overlay.Get(option); err == nil {
+			s, section = overlay, overlay.Name
 		}
 	}
 
-	return nil
-}
-
-func (p *ConfigParser) allOptions() ([]string, error) {
-	sections := p.Sections()
-	options := make([]string, 0)
-	for _, s := range sections {
-		o, err := p.Options(s)
-		if err != nil {
-			return nil, err
-		}
-
-		options = append(options, o...)
+	old, _ := s.Get(option)
+	if err := s.Remove(option); err != nil {
+		return err
 	}
+	p.notify(Change{Kind: OptionRemoved, Section: section, Option: option, OldValue: old})
 
-	return options, nil
+	return nil
 }
 
 func defaultGet(value string) (any, error) { return value, nil }
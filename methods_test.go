@@ -361,10 +361,23 @@ func (s *ConfigParserSuite) TestRemoveOption(c *gc.C) {
 	c.Assert(hasOption, gc.Equals, false)
 }
 
+// RemoveOption(section, option) removes options matching the specified option
+// case-insensitively by default.
+func (s *ConfigParserSuite) TestRemoveOptionMatchesCaseInsensitively(c *gc.C) {
+	assertSuccessful(c, s.p.RemoveOption("follower", "max_build_TIME"))
+
+	result, err := s.p.HasOption("follower", "max_build_time")
+	c.Assert(err, gc.IsNil)
+	c.Assert(result, gc.Equals, false)
+}
+
 // RemoveOption(section, option) does not remove options when the option doesn't
-// match the specified option exactly.
+// match the specified option exactly with the identity option transform.
 func (s *ConfigParserSuite) TestRemoveOptionMatchesPrecisely(c *gc.C) {
-	err := s.p.RemoveOption("follower", "max_build_TIME")
+	p, err := configparser.ParseWithOptions("testdata/example.cfg", configparser.OptionTransform(nil))
+	c.Assert(err, gc.IsNil)
+
+	err = p.RemoveOption("follower", "max_build_TIME")
 	c.Assert(err, gc.ErrorMatches, "no option \"max_build_TIME\" in section: \"follower\"")
 }
 
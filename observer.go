@@ -0,0 +1,176 @@
+package configparser
+
+import (
+	"fmt"
+	"path"
+)
+
+// ChangeKind describes the kind of mutation reported by a Change.
+type ChangeKind int
+
+// Available kinds of Change.
+const (
+	OptionAdded ChangeKind = iota
+	OptionChanged
+	OptionRemoved
+	SectionAdded
+	SectionRemoved
+)
+
+// String returns a human readable name of the kind.
+func (k ChangeKind) String() string {
+	switch k {
+	case OptionAdded:
+		return "option added"
+	case OptionChanged:
+		return "option changed"
+	case OptionRemoved:
+		return "option removed"
+	case SectionAdded:
+		return "section added"
+	case SectionRemoved:
+		return "section removed"
+	}
+
+	return "unknown"
+}
+
+// Change describes a single mutation of the ConfigParser.
+//
+// Option, OldValue and NewValue are empty for section changes. OldValues and
+// NewValues are set by Diff only, for the options with more than one value
+// collected from the repeated keys, see DuplicateCollect.
+type Change struct {
+	Kind      ChangeKind
+	Section   string
+	Option    string
+	OldValue  string
+	NewValue  string
+	OldValues []string
+	NewValues []string
+}
+
+// ChangeFunc is called after a mutation of the ConfigParser.
+type ChangeFunc func(Change)
+
+// ChangeSetFunc is called once with all the changes applied together
+// by a single mutation or a committed transaction.
+type ChangeSetFunc func([]Change)
+
+// subscription holds a registered callback with its filters.
+type subscription struct {
+	fn      ChangeFunc
+	setFn   ChangeSetFunc
+	section string
+	option  string
+}
+
+// matches checks if the change passes the subscription filters.
+// Section changes never match an option filter. Patterns were checked
+// when subscribing, so there can't be errors.
+func (s *subscription) matches(c Change) bool {
+	if s.section != "" {
+		if ok, _ := path.Match(s.section, c.Section); !ok {
+			return false
+		}
+	}
+	if s.option != "" {
+		if c.Option == "" {
+			return false
+		}
+		if ok, _ := path.Match(s.option, c.Option); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+type subscribeOptFunc func(*subscription)
+
+// SectionFilter limits a subscription to the sections matching the glob
+// pattern, see [path.Match] for the syntax.
+func SectionFilter(glob string) subscribeOptFunc {
+	return func(s *subscription) {
+		s.section = glob
+	}
+}
+
+// OptionFilter limits a subscription to the options matching the glob
+// pattern, see [path.Match] for the syntax.
+func OptionFilter(glob string) subscribeOptFunc {
+	return func(s *subscription) {
+		s.option = glob
+	}
+}
+
+// Subscribe registers fn to be called after each successful call of Set,
+// RemoveOption, AddSection and RemoveSection.
+//
+// Returns a function which removes the subscription.
+// Returns an error if a filter pattern is malformed.
+func (p *ConfigParser) Subscribe(fn ChangeFunc, opts ...subscribeOptFunc) (func(), error) {
+	s := &subscription{fn: fn}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return p.subscribe(s)
+}
+
+// SubscribeChangeSet registers fn to be called once per mutation or
+// committed transaction with all the changes matching the filters.
+//
+// Returns a function which removes the subscription.
+// Returns an error if a filter pattern is malformed.
+func (p *ConfigParser) SubscribeChangeSet(fn ChangeSetFunc, opts ...subscribeOptFunc) (func(), error) {
+	s := &subscription{setFn: fn}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return p.subscribe(s)
+}
+
+func (p *ConfigParser) subscribe(s *subscription) (func(), error) {
+	for _, pattern := range []string{s.section, s.option} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid filter %q: %w", pattern, err)
+		}
+	}
+	p.subscriptions = append(p.subscriptions, s)
+
+	return func() {
+		for i, sub := range p.subscriptions {
+			if sub == s {
+				p.subscriptions = append(p.subscriptions[:i:i], p.subscriptions[i+1:]...)
+				return
+			}
+		}
+	}, nil
+}
+
+// notify calls the subscribers matching the given changes.
+func (p *ConfigParser) notify(changes ...Change) {
+	// Copy the subscriptions, so they can unsubscribe from the callback.
+	subs := append([]*subscription(nil), p.subscriptions...)
+	for _, s := range subs {
+		matched := make([]Change, 0, len(changes))
+		for _, c := range changes {
+			if s.matches(c) {
+				matched = append(matched, c)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+
+		if s.setFn != nil {
+			s.setFn(matched)
+			continue
+		}
+		for _, c := range matched {
+			s.fn(c)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package configparser_test
+
+import (
+	"github.com/bigkevmcd/go-configparser"
+
+	gc "gopkg.in/check.v1"
+)
+
+// Subscribe(fn) should report every mutation after it was applied.
+func (s *ConfigParserSuite) TestSubscribe(c *gc.C) {
+	p := configparser.New()
+	var changes []configparser.Change
+	p.Subscribe(func(ch configparser.Change) {
+		changes = append(changes, ch)
+	})
+
+	assertSuccessful(c, p.AddSection("db"))
+	assertSuccessful(c, p.Set("db", "host", "localhost"))
+	assertSuccessful(c, p.Set("db", "host", "remote"))
+	assertSuccessful(c, p.RemoveOption("db", "host"))
+	assertSuccessful(c, p.RemoveSection("db"))
+
+	c.Assert(changes, gc.DeepEquals, []configparser.Change{
+		{Kind: configparser.SectionAdded, Section: "db"},
+		{Kind: configparser.OptionAdded, Section: "db", Option: "host", NewValue: "localhost"},
+		{Kind: configparser.OptionChanged, Section: "db", Option: "host", OldValue: "localhost", NewValue: "remote"},
+		{Kind: configparser.OptionRemoved, Section: "db", Option: "host", OldValue: "remote"},
+		{Kind: configparser.SectionRemoved, Section: "db"},
+	})
+}
+
+// Subscribe(fn) should not report failed mutations.
+func (s *ConfigParserSuite) TestSubscribeFailedMutation(c *gc.C) {
+	called := false
+	s.p.Subscribe(func(configparser.Change) { called = true })
+
+	c.Assert(s.p.Set("unknown", "option", "value"), gc.NotNil)
+	c.Assert(s.p.RemoveOption("follower", "unknown"), gc.NotNil)
+	c.Assert(called, gc.Equals, false)
+}
+
+// Subscribe(fn, filters...) should only report changes matching the filters.
+func (s *ConfigParserSuite) TestSubscribeWithFilters(c *gc.C) {
+	var options []string
+	_, err := s.p.Subscribe(func(ch configparser.Change) {
+		options = append(options, ch.Section+"."+ch.Option)
+	}, configparser.SectionFilter("fol*"), configparser.OptionFilter("*_time"))
+	c.Assert(err, gc.IsNil)
+
+	assertSuccessful(c, s.p.AddSection("follower2"))
+	assertSuccessful(c, s.p.Set("follower", "max_build_time", "100"))
+	assertSuccessful(c, s.p.Set("follower", "log_dir", "/tmp"))
+	assertSuccessful(c, s.p.Set("whitespace", "min_time", "1"))
+	assertSuccessful(c, s.p.Set("follower2", "min_time", "1"))
+
+	c.Assert(options, gc.DeepEquals, []string{"follower.max_build_time", "follower2.min_time"})
+}
+
+// The function returned by Subscribe should remove the subscription.
+func (s *ConfigParserSuite) TestUnsubscribe(c *gc.C) {
+	count := 0
+	unsubscribe, err := s.p.Subscribe(func(configparser.Change) { count++ })
+	c.Assert(err, gc.IsNil)
+
+	assertSuccessful(c, s.p.Set("follower", "option", "1"))
+	unsubscribe()
+	assertSuccessful(c, s.p.Set("follower", "option", "2"))
+
+	c.Assert(count, gc.Equals, 1)
+}
+
+// Subscribe(fn, filters...) should reject malformed filter patterns.
+func (s *ConfigParserSuite) TestSubscribeInvalidFilter(c *gc.C) {
+	_, err := s.p.Subscribe(func(configparser.Change) {}, configparser.SectionFilter("[db"))
+	c.Assert(err, gc.ErrorMatches, `invalid filter "\[db": syntax error in pattern`)
+	_, err = s.p.SubscribeChangeSet(func([]configparser.Change) {}, configparser.OptionFilter("[db"))
+	c.Assert(err, gc.ErrorMatches, `invalid filter "\[db": syntax error in pattern`)
+}
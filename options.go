@@ -2,13 +2,18 @@ package configparser
 
 import (
 	"fmt"
+	"io/fs"
 	"regexp"
 	"strings"
 
 	"github.com/bigkevmcd/go-configparser/chainmap"
 )
 
-const defaultSectionName = "DEFAULT"
+const (
+	defaultSectionName     = "DEFAULT"
+	defaultExtendsKey      = "__extends__"
+	defaultMaxIncludeDepth = 10
+)
 
 // options allows to control parser behavior.
 type options struct {
@@ -22,11 +27,38 @@ type options struct {
 	allowNoValue          bool
 	emptyLines            bool
 	strict                bool
+	includeDirective      string
+	includeSection        string
+	includeOption         string
+	includeRoot           string
+	maxIncludeDepth       int
+	conditions            ConditionEvaluator
+	fsys                  fileSystem
+	hierarchySeparator    string
+	extendsKey            string
+	profile               string
+	duplicateKeys         DuplicateKeyPolicy
+	duplicateSections     DuplicateSectionPolicy
+	optionTransform       func(string) string
+	sectionTransform      func(string) string
+	sectionMatcher        func(string) (string, bool)
+	sectionValidator      func(string) error
+	allowUnnamedSection   bool
+	listUnnamedSection    bool
+	// sectionHeaderErr is set if the section header regular expression
+	// is invalid, see SectionHeader.
+	sectionHeaderErr error
+	// customInterpolation is set if the interpolator was set with
+	// Interpolation, the default one is created per lookup.
+	customInterpolation bool
 }
 
 func (o *options) compileRegex() (
 	keyValue *regexp.Regexp, keyWNoValue *regexp.Regexp, err error,
 ) {
+	if o.sectionHeaderErr != nil {
+		return nil, nil, o.sectionHeaderErr
+	}
 	if o.allowNoValue {
 		keyWNoValue, err = regexp.Compile(
 			fmt.Sprintf(
@@ -79,6 +111,9 @@ func (pr Prefixes) HasPrefix(str string) bool {
 
 // Split splits str with the first prefix found.
 // Returns original string if no matches.
+//
+// Unlike inline comment parsing, Split does not require whitespace before
+// the prefix and ignores quotes and escapes.
 func (pr Prefixes) Split(str string) string {
 	for _, p := range pr {
 		if strings.Contains(str, p) {
@@ -89,6 +124,37 @@ func (pr Prefixes) Split(str string) string {
 	return str
 }
 
+// DuplicateKeyPolicy defines handling of the keys repeated within a section
+// of the parsed input.
+type DuplicateKeyPolicy int
+
+// Available duplicate key policies.
+const (
+	// DuplicateOverride keeps the last value of the repeated key.
+	DuplicateOverride DuplicateKeyPolicy = iota
+	// DuplicateKeepFirst keeps the first value of the repeated key.
+	DuplicateKeepFirst
+	// DuplicateError returns an error for the repeated key.
+	DuplicateError
+	// DuplicateCollect keeps all values of the repeated key, see GetAll.
+	// Get returns the last value.
+	DuplicateCollect
+)
+
+// DuplicateSectionPolicy defines handling of the repeated section headers.
+type DuplicateSectionPolicy int
+
+// Available duplicate section policies.
+const (
+	// DuplicateSectionMerge continues the existing section.
+	DuplicateSectionMerge DuplicateSectionPolicy = iota
+	// DuplicateSectionError returns an error for the repeated section.
+	DuplicateSectionError
+	// DuplicateSectionList starts a new instance of the section, see
+	// SectionInstances. Lookups use the first instance.
+	DuplicateSectionList
+)
+
 // Interpolator defines interpolation instance.
 // For more details, check [chainmap.ChainMap] realisation.
 type Interpolator interface {
@@ -105,6 +171,11 @@ func defaultOptions() *options {
 		delimiters:        ":=",
 		commentPrefixes:   Prefixes{"#", ";"},
 		multilinePrefixes: Prefixes{"\t", " "},
+		maxIncludeDepth:   defaultMaxIncludeDepth,
+		fsys:              osFileSystem{},
+		optionTransform:   strings.ToLower,
+		sectionTransform:  identity,
+		sectionMatcher:    matchSectionHeader(sectionHeader),
 		converters: Converter{
 			StringConv: defaultGet,
 			IntConv:    defaultGetInt64,
@@ -119,7 +190,7 @@ type optFunc func(*options)
 // Interpolation sets custom interpolator.
 func Interpolation(i Interpolator) optFunc {
 	return func(o *options) {
-		o.interpolation = i
+		o.interpolation, o.customInterpolation = i, true
 	}
 }
 
@@ -133,8 +204,9 @@ func CommentPrefixes(pr Prefixes) optFunc {
 }
 
 // InlineCommentPrefixes sets a slice of inline comment delimiters.
-// When parsing a value, it will be split with
-// the first match in this slice.
+// When parsing a value, the inline comment starts with the first prefix
+// at the beginning of the value or after whitespace, which is not quoted or
+// escaped with a backslash, e.g. `\#`. Comments are retained, see Comment.
 func InlineCommentPrefixes(pr Prefixes) optFunc {
 	return func(o *options) {
 		o.inlineCommentPrefixes = pr
@@ -179,8 +251,216 @@ func Converters(conv Converter) optFunc {
 // AllowNoValue allows option with no value to be saved as empty line.
 func AllowNoValue(o *options) { o.allowNoValue = true }
 
-// Strict prohibits the duplicates of options and values.
+// Strict prohibits the duplicates of sections and of options within
+// a section, including DEFAULT, in one source. Option names are compared
+// by their lookup keys, see OptionTransform.
 func Strict(o *options) { o.strict = true }
 
+// DuplicateKeys sets the handling of the keys repeated within a section of
+// the parsed input, defaults to DuplicateOverride.
+func DuplicateKeys(policy DuplicateKeyPolicy) optFunc {
+	return func(o *options) {
+		o.duplicateKeys = policy
+	}
+}
+
+// DuplicateSections sets the handling of the repeated section headers,
+// defaults to DuplicateSectionMerge.
+func DuplicateSections(policy DuplicateSectionPolicy) optFunc {
+	return func(o *options) {
+		o.duplicateSections = policy
+	}
+}
+
+// AllowUnnamedSection allows options before the first section header, which
+// are stored in the UnnamedSection and written back without the header.
+func AllowUnnamedSection(o *options) { o.allowUnnamedSection = true }
+
+// ListUnnamedSection includes the UnnamedSection in Sections.
+func ListUnnamedSection(o *options) { o.listUnnamedSection = true }
+
 // AllowEmptyLines allows empty lines in multiline values.
 func AllowEmptyLines(o *options) { o.emptyLines = true }
+
+// AllowIncludes enables both "%include path" directive lines and
+// "[include]" section with "files" option listing the included files.
+func AllowIncludes(o *options) {
+	o.includeDirective = "%include"
+	o.includeSection, o.includeOption = "include", "files"
+}
+
+// IncludeDirective enables include directive lines, which start with the
+// given directive followed by the path of the included files.
+//
+// Paths are relative to the including file and may contain glob patterns,
+// matching files are parsed in lexical order.
+func IncludeDirective(directive string) optFunc {
+	return func(o *options) {
+		o.includeDirective = directive
+	}
+}
+
+// IncludeSection enables the named section, which lists whitespace separated
+// paths of the included files in the given option. The section itself is not
+// stored.
+func IncludeSection(section, option string) optFunc {
+	return func(o *options) {
+		o.includeSection, o.includeOption = section, option
+	}
+}
+
+// IncludeRoot prohibits including files outside of the given directory.
+func IncludeRoot(dir string) optFunc {
+	return func(o *options) {
+		o.includeRoot = dir
+	}
+}
+
+// MaxIncludeDepth sets the maximum nesting level of included files.
+func MaxIncludeDepth(n int) optFunc {
+	return func(o *options) {
+		o.maxIncludeDepth = n
+	}
+}
+
+// ConditionalIncludes enables `[includeIf "condition"]` sections, which
+// include the files listed in the "path" option when the condition is
+// evaluated as true. Nil evaluator defaults to Conditions.
+func ConditionalIncludes(eval ConditionEvaluator) optFunc {
+	return func(o *options) {
+		if eval == nil {
+			eval = Conditions{}
+		}
+		o.conditions = eval
+	}
+}
+
+// FileSystem sets the file system used to read the files by all file based
+// features, e.g. includes and drop-in directories.
+func FileSystem(fsys fs.FS) optFunc {
+	return func(o *options) {
+		o.fsys = ioFileSystem{fsys: fsys}
+	}
+}
+
+// HierarchicalSections enables hierarchical section names separated by sep,
+// e.g. "server.eu.fra1", where lookups fall back to the parent sections
+// "server.eu" and "server" before the defaults.
+func HierarchicalSections(sep string) optFunc {
+	return func(o *options) {
+		o.hierarchySeparator = sep
+	}
+}
+
+// Inheritance enables section inheritance with the "__extends__" option,
+// see ExtendsKey.
+func Inheritance(o *options) { o.extendsKey = defaultExtendsKey }
+
+// ExtendsKey enables section inheritance with the given option, which lists
+// comma or whitespace separated names of the sections to inherit from.
+// Lookups follow the inherited sections in the C3 order, so a shared base
+// follows all the sections inheriting from it, before the defaults.
+// Inconsistent orders are reported as errors.
+func ExtendsKey(key string) optFunc {
+	return func(o *options) {
+		o.extendsKey = key
+	}
+}
+
+// Profile activates the profile, so the overlay sections named
+// "section:profile" take precedence over the sections, see
+// ConfigParser.WithProfile.
+func Profile(name string) optFunc {
+	return func(o *options) {
+		o.profile = name
+	}
+}
+
+// OptionTransform sets the function returning the lookup key of an option
+// name, which defines equivalent names, e.g. to make them case-sensitive
+// or treat hyphens and underscores the same. Defaults to strings.ToLower,
+// nil keeps the names as written.
+//
+// Options keep the spelling they were first added with.
+func OptionTransform(fn func(string) string) optFunc {
+	return func(o *options) {
+		if fn == nil {
+			fn = identity
+		}
+		o.optionTransform = fn
+	}
+}
+
+// SectionTransform sets the function returning the lookup key of a section
+// name, e.g. strings.ToLower makes the section names case-insensitive.
+// Section names are kept as written by default or if nil.
+func SectionTransform(fn func(string) string) optFunc {
+	return func(o *options) {
+		if fn == nil {
+			fn = identity
+		}
+		o.sectionTransform = fn
+	}
+}
+
+func identity(s string) string { return s }
+
+// SectionHeader sets the regular expression recognizing the section header
+// lines, like Python's SECTCRE. Section name is the subexpression named
+// "header" or the first one, e.g. `^\[\s*(?P<header>[^]]+?)\s*\]` trims
+// the spaces around the name. Lines are matched with the surrounding spaces
+// removed.
+//
+// Nil restores the default expression. Parsing returns an error if the
+// expression has no subexpressions.
+func SectionHeader(re *regexp.Regexp) optFunc {
+	return func(o *options) {
+		if re == nil {
+			re = sectionHeader
+		}
+		if re.NumSubexp() == 0 {
+			o.sectionHeaderErr = fmt.Errorf("section header %q has no subexpression", re)
+			return
+		}
+		o.sectionMatcher, o.sectionHeaderErr = matchSectionHeader(re), nil
+	}
+}
+
+// SectionMatcher sets the function recognizing the section header lines,
+// which returns the section name and true for the header lines. Lines are
+// matched with the surrounding spaces removed.
+func SectionMatcher(fn func(line string) (string, bool)) optFunc {
+	return func(o *options) {
+		if fn == nil {
+			fn = matchSectionHeader(sectionHeader)
+		}
+		o.sectionMatcher, o.sectionHeaderErr = fn, nil
+	}
+}
+
+// SectionNameValidator sets the function validating the names of sections,
+// both parsed and added with AddSection. Names of the default and include
+// sections are not validated.
+func SectionNameValidator(fn func(name string) error) optFunc {
+	return func(o *options) {
+		o.sectionValidator = fn
+	}
+}
+
+// matchSectionHeader returns the section matcher using the regular
+// expression, see SectionHeader.
+func matchSectionHeader(re *regexp.Regexp) func(string) (string, bool) {
+	group := 1
+	if i := re.SubexpIndex("header"); i > 0 {
+		group = i
+	}
+
+	return func(line string) (string, bool) {
+		match := re.FindStringSubmatch(line)
+		if len(match) <= group {
+			return "", false
+		}
+
+		return match[group], true
+	}
+}
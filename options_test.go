@@ -1,6 +1,8 @@
 package configparser_test
 
 import (
+	"errors"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -51,6 +53,40 @@ func (s *ConfigParserSuite) TestInterpolationOpt(c *C) {
 	c.Assert(v, Equals, "/new/home/something")
 }
 
+// TestInterpolationOptItems tests the custom interpolator gets the sections
+// added once for all the items.
+func (s *ConfigParserSuite) TestInterpolationOptItems(c *C) {
+	interpolator := newCustomInterpolator()
+	parsed, err := configparser.ParseReaderWithOptions(
+		strings.NewReader("[DEFAULT]\ndir=/home\n[paths]\npath=%(dir)s/something\nother=%(dir)s/other\n\n"),
+		configparser.Interpolation(interpolator),
+	)
+	c.Assert(err, IsNil)
+
+	items, err := parsed.ItemsWithDefaultsInterpolated("paths")
+	c.Assert(err, IsNil)
+	c.Assert(items, DeepEquals, configparser.Dict{
+		"dir":   "/home",
+		"path":  "/new/home/something",
+		"other": "/new/home/other",
+	})
+	c.Assert(interpolator.Len(), Equals, 2)
+}
+
+// TestInterpolationOptChainMap tests a chainmap seeded with global
+// interpolation variables.
+func (s *ConfigParserSuite) TestInterpolationOptChainMap(c *C) {
+	parsed, err := configparser.ParseReaderWithOptions(
+		strings.NewReader("[section]\nx=%(seed)s\n\n"),
+		configparser.Interpolation(chainmap.New(chainmap.Dict{"seed": "S"})),
+	)
+	c.Assert(err, IsNil)
+
+	v, err := parsed.GetInterpolated("section", "x")
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, "S")
+}
+
 // TestCommentPrefixesOpt tests custom comment prefixes.
 func (s *ConfigParserSuite) TestCommentPrefixesOpt(c *C) {
 	parsed, err := configparser.ParseReaderWithOptions(
@@ -80,6 +116,93 @@ option=value // this is an inline comment
 	c.Assert(v, Equals, "value")
 }
 
+// TestInlineCommentsRespectWhitespaceAndQuotes tests that inline comments
+// start after whitespace outside of quotes and are written back.
+func (s *ConfigParserSuite) TestInlineCommentsRespectWhitespaceAndQuotes(c *C) {
+	parsed, err := configparser.ParseReaderWithOptions(
+		strings.NewReader(`[a # b]
+url = http://host/#frag
+value = 42 # the answer
+quoted = "a # b" # quoted
+escaped = a \# b
+`),
+		configparser.InlineCommentPrefixes(configparser.Prefixes{"#"}),
+	)
+	c.Assert(err, IsNil)
+	c.Assert(parsed.Sections(), DeepEquals, []string{"a # b"})
+
+	for option, expected := range map[string]string{
+		"url":     "http://host/#frag",
+		"value":   "42",
+		"quoted":  `"a # b"`,
+		"escaped": "a # b",
+	} {
+		v, err := parsed.Get("a # b", option)
+		c.Assert(err, IsNil)
+		c.Assert(v, Equals, expected)
+	}
+
+	comment, err := parsed.Comment("a # b", "value")
+	c.Assert(err, IsNil)
+	c.Assert(comment, Equals, "# the answer")
+	comment, err = parsed.Comment("a # b", "url")
+	c.Assert(err, IsNil)
+	c.Assert(comment, Equals, "")
+	_, err = parsed.Comment("a # b", "unknown")
+	c.Assert(err, NotNil)
+
+	c.Assert(parsed.SetComment("a # b", "url", "# link"), IsNil)
+	// Replaced values drop their comments.
+	c.Assert(parsed.Set("a # b", "quoted", `"c # d"`), IsNil)
+	var buf strings.Builder
+	c.Assert(parsed.WriteWithDelimiter(&buf, "="), IsNil)
+	c.Assert(buf.String(), Equals, `[a # b]
+escaped = a \# b
+quoted = "c # d"
+url = http://host/#frag # link
+value = 42 # the answer
+
+`)
+}
+
+// TestInlineCommentsRoundTrip tests that inline comments of multiline values
+// and repeated keys survive writing and parsing back.
+func (s *ConfigParserSuite) TestInlineCommentsRoundTrip(c *C) {
+	input := `[section]
+k = 1 # one
+k = 2
+multi = a # c
+  b
+`
+	expected := `[section]
+k = 1 # one
+k = 2
+multi = a # c
+	b
+
+`
+	for i := 0; i < 2; i++ {
+		parsed, err := configparser.ParseReaderWithOptions(
+			strings.NewReader(input),
+			configparser.InlineCommentPrefixes(configparser.Prefixes{"#"}),
+			configparser.DuplicateKeys(configparser.DuplicateCollect),
+		)
+		c.Assert(err, IsNil)
+
+		v, err := parsed.Get("section", "multi")
+		c.Assert(err, IsNil)
+		c.Assert(v, Equals, "a\nb")
+		comment, err := parsed.Comment("section", "k")
+		c.Assert(err, IsNil)
+		c.Assert(comment, Equals, "# one")
+
+		var buf strings.Builder
+		c.Assert(parsed.WriteWithDelimiter(&buf, "="), IsNil)
+		c.Assert(buf.String(), Equals, expected)
+		input = buf.String()
+	}
+}
+
 // TestDefalutSectionOpt tests custom default section name.
 func (s *ConfigParserSuite) TestDefalutSectionOpt(c *C) {
 	parsed, err := configparser.ParseReaderWithOptions(
@@ -199,30 +322,74 @@ func (s *ConfigParserSuite) TestStrictOptDuplicateSection(c *C) {
 	)
 
 	c.Assert(err, NotNil)
-	c.Assert(err.Error(), Equals, "section \"dubl\" already exists and strict flag was set")
+	c.Assert(err.Error(), Equals, "section \"dubl\" already exists on line 1 and strict flag was set: 4")
 }
 
 // TestStrictOptDuplicateValue tests strict option with value duplicate.
 func (s *ConfigParserSuite) TestStrictOptDuplicateValue(c *C) {
 	_, err := configparser.ParseReaderWithOptions(
-		strings.NewReader("[section1]\ndubl=1\n\n[section2]\ndubl=2\n\n"),
+		strings.NewReader("[section1]\ndubl=1\nother=1\nDubl=2\n\n"),
 		configparser.Strict,
 	)
 
 	c.Assert(err, NotNil)
-	c.Assert(err.Error(), Equals, "option \"dubl\" already exists and strict flag was set")
+	c.Assert(err.Error(), Equals, "option \"Dubl\" in section \"section1\" already exists on line 2 and strict flag was set: 4")
 }
 
 // TestStrictOptDuplicateEmptyValue tests strict option with empty value duplicate.
 func (s *ConfigParserSuite) TestStrictOptDuplicateEmptyValue(c *C) {
 	_, err := configparser.ParseReaderWithOptions(
-		strings.NewReader("[section1]\ndubl\n\n[section2]\ndubl\n\n"),
+		strings.NewReader("[section1]\ndubl\ndubl\n\n"),
 		configparser.Strict,
 		configparser.AllowNoValue,
 	)
 
 	c.Assert(err, NotNil)
-	c.Assert(err.Error(), Equals, "option \"dubl\" already exists and strict flag was set")
+	c.Assert(err.Error(), Equals, "option \"dubl\" in section \"section1\" already exists on line 2 and strict flag was set: 3")
+}
+
+// TestStrictOptSameOptionInSections tests strict option allows the same
+// option in different sections.
+func (s *ConfigParserSuite) TestStrictOptSameOptionInSections(c *C) {
+	parsed, err := configparser.ParseReaderWithOptions(
+		strings.NewReader("[DEFAULT]\nhost=default\n\n[section1]\nhost=1\n\n[section2]\nhost=2\n\n"),
+		configparser.Strict,
+	)
+	c.Assert(err, IsNil)
+
+	v, err := parsed.Get("section2", "host")
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, "2")
+}
+
+// TestStrictOptDuplicateDefault tests strict option with duplicate in
+// the repeated DEFAULT section.
+func (s *ConfigParserSuite) TestStrictOptDuplicateDefault(c *C) {
+	_, err := configparser.ParseReaderWithOptions(
+		strings.NewReader("[DEFAULT]\nhost=1\n\n[section1]\nport=1\n\n[DEFAULT]\nHOST=2\n"),
+		configparser.Strict,
+	)
+
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, "option \"HOST\" in section \"DEFAULT\" already exists on line 2 and strict flag was set: 8")
+}
+
+// TestStrictOptSectionInFiles tests strict option allows the same section
+// in different files.
+func (s *ConfigParserSuite) TestStrictOptSectionInFiles(c *C) {
+	dir := c.MkDir()
+	writeFiles(c, dir, map[string]string{
+		"10.conf": "[svc]\nhost=localhost\n",
+		"20.conf": "[svc]\nhost=example.com\nport=80\n",
+	})
+
+	p := configparser.NewWithOptions(configparser.Strict)
+	_, err := p.ReadDir(dir, "*.conf")
+	c.Assert(err, IsNil)
+
+	items, err := p.Items("svc")
+	c.Assert(err, IsNil)
+	c.Assert(items, DeepEquals, configparser.Dict{"host": "example.com", "port": "80"})
 }
 
 // TestAllowEmptyLines tests empty lines as part of the value.
@@ -263,3 +430,237 @@ option = this value will have
 		"option": "this value will have\nits multiline",
 	})
 }
+
+const duplicateKeysConfig = `[Service]
+ExecStartPre = /bin/mkdir -p /run/app
+ExecStart = /usr/bin/app
+ExecStartPre = /bin/chown app /run/app
+`
+
+// TestDuplicateKeys tests the override and keep first duplicate key policies.
+func (s *ConfigParserSuite) TestDuplicateKeys(c *C) {
+	for policy, expected := range map[configparser.DuplicateKeyPolicy]string{
+		configparser.DuplicateOverride:  "/bin/chown app /run/app",
+		configparser.DuplicateKeepFirst: "/bin/mkdir -p /run/app",
+	} {
+		parsed, err := configparser.ParseReaderWithOptions(
+			strings.NewReader(duplicateKeysConfig),
+			configparser.DuplicateKeys(policy),
+		)
+		c.Assert(err, IsNil)
+		v, err := parsed.Get("Service", "ExecStartPre")
+		c.Assert(err, IsNil)
+		c.Assert(v, Equals, expected)
+		values, err := parsed.GetAll("Service", "ExecStartPre")
+		c.Assert(err, IsNil)
+		c.Assert(values, DeepEquals, []string{expected})
+	}
+}
+
+// TestDuplicateKeysError tests the error duplicate key policy.
+func (s *ConfigParserSuite) TestDuplicateKeysError(c *C) {
+	_, err := configparser.ParseReaderWithOptions(
+		strings.NewReader(duplicateKeysConfig),
+		configparser.DuplicateKeys(configparser.DuplicateError),
+	)
+	c.Assert(err, ErrorMatches, `option "ExecStartPre" in section "Service" already defined on line 2: 4`)
+}
+
+// TestDuplicateKeysCollect tests collecting all values of the repeated keys.
+func (s *ConfigParserSuite) TestDuplicateKeysCollect(c *C) {
+	parsed, err := configparser.ParseReaderWithOptions(
+		strings.NewReader(duplicateKeysConfig),
+		configparser.DuplicateKeys(configparser.DuplicateCollect),
+	)
+	c.Assert(err, IsNil)
+
+	values, err := parsed.GetAll("Service", "execstartpre")
+	c.Assert(err, IsNil)
+	c.Assert(values, DeepEquals, []string{"/bin/mkdir -p /run/app", "/bin/chown app /run/app"})
+	v, err := parsed.Get("Service", "ExecStartPre")
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, "/bin/chown app /run/app")
+	origin, err := parsed.Origin("Service", "ExecStartPre")
+	c.Assert(err, IsNil)
+	c.Assert(origin.Line, Equals, 4)
+	_, err = parsed.GetAll("Service", "ExecStop")
+	c.Assert(err, ErrorMatches, `no option "ExecStop" in section: "Service"`)
+
+	var buf strings.Builder
+	c.Assert(parsed.WriteWithDelimiter(&buf, "="), IsNil)
+	c.Assert(buf.String(), Equals, `[Service]
+ExecStart = /usr/bin/app
+ExecStartPre = /bin/mkdir -p /run/app
+ExecStartPre = /bin/chown app /run/app
+
+`)
+
+	// Set replaces all values.
+	assertSuccessful(c, parsed.Set("Service", "ExecStartPre", "/bin/true"))
+	values, err = parsed.GetAll("Service", "ExecStartPre")
+	c.Assert(err, IsNil)
+	c.Assert(values, DeepEquals, []string{"/bin/true"})
+}
+
+// TestOptionTransform tests custom option name transform applied to the
+// lookups, interpolation and the writer.
+func (s *ConfigParserSuite) TestOptionTransform(c *C) {
+	parsed, err := configparser.ParseReaderWithOptions(
+		strings.NewReader(`[server]
+max-conn = 10
+Max_Conn = 20
+url = http://%(host-name)s:%(max_conn)s
+host_name = example.com
+`),
+		configparser.OptionTransform(func(s string) string {
+			return strings.ReplaceAll(strings.ToLower(s), "-", "_")
+		}),
+	)
+	c.Assert(err, IsNil)
+
+	v, err := parsed.Get("server", "MAX_CONN")
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, "20")
+	v, err = parsed.GetInterpolated("server", "url")
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, "http://example.com:20")
+
+	assertSuccessful(c, parsed.Set("server", "host-name", "localhost"))
+	ok, err := parsed.HasOption("server", "HOST-NAME")
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+	assertSuccessful(c, parsed.RemoveOption("server", "url"))
+
+	var buf strings.Builder
+	c.Assert(parsed.WriteWithDelimiter(&buf, "="), IsNil)
+	c.Assert(buf.String(), Equals, "[server]\nhost_name = localhost\nmax-conn = 20\n\n")
+}
+
+// TestOptionTransformIdentity tests case-sensitive option names.
+func (s *ConfigParserSuite) TestOptionTransformIdentity(c *C) {
+	parsed, err := configparser.ParseReaderWithOptions(
+		strings.NewReader("[section]\nName = upper\nname = lower\nref = %(Name)s\n"),
+		configparser.OptionTransform(nil),
+	)
+	c.Assert(err, IsNil)
+
+	result, err := parsed.Items("section")
+	c.Assert(err, IsNil)
+	c.Assert(result, DeepEquals, configparser.Dict{"Name": "upper", "name": "lower", "ref": "%(Name)s"})
+	v, err := parsed.GetInterpolated("section", "ref")
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, "upper")
+	_, err = parsed.Get("section", "NAME")
+	c.Assert(err, ErrorMatches, `no option "NAME" in section: "section"`)
+}
+
+// TestSectionTransform tests case-insensitive section names.
+func (s *ConfigParserSuite) TestSectionTransform(c *C) {
+	parsed, err := configparser.ParseReaderWithOptions(
+		strings.NewReader("[default]\nbase = /srv\n\n[Server]\nhost = a\n\n[SERVER]\nport = 80\n"),
+		configparser.SectionTransform(strings.ToLower),
+	)
+	c.Assert(err, IsNil)
+
+	c.Assert(parsed.Sections(), DeepEquals, []string{"Server"})
+	result, err := parsed.ItemsWithDefaults("server")
+	c.Assert(err, IsNil)
+	c.Assert(result, DeepEquals, configparser.Dict{"base": "/srv", "host": "a", "port": "80"})
+	c.Assert(parsed.HasSection("sErVeR"), Equals, true)
+	err = parsed.AddSection("server")
+	c.Assert(err, ErrorMatches, `section "server" already exists`)
+	err = parsed.AddSection("Default")
+	c.Assert(err, ErrorMatches, `invalid section name: "Default"`)
+	items, err := parsed.Items("default")
+	c.Assert(err, IsNil)
+	c.Assert(items, DeepEquals, configparser.Dict{"base": "/srv"})
+	assertSuccessful(c, parsed.RemoveSection("SERVER"))
+	c.Assert(parsed.Sections(), HasLen, 0)
+}
+
+// TestSectionHeader tests custom section header regular expression.
+func (s *ConfigParserSuite) TestSectionHeader(c *C) {
+	parsed, err := configparser.ParseReaderWithOptions(
+		strings.NewReader("[ server ]\nhost = a\n\n{ other }\nkey = b\n"),
+		configparser.SectionHeader(regexp.MustCompile(`^[\[{]\s*(?P<header>[^]}]+?)\s*[\]}]`)),
+	)
+	c.Assert(err, IsNil)
+
+	c.Assert(parsed.Sections(), DeepEquals, []string{"other", "server"})
+	v, err := parsed.Get("server", "host")
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, "a")
+
+	_, err = configparser.ParseReaderWithOptions(
+		strings.NewReader("[server]\nhost = a\n"),
+		configparser.SectionHeader(regexp.MustCompile(`^\[[^]]+\]`)),
+	)
+	c.Assert(err, ErrorMatches, `section header ".*" has no subexpression`)
+
+	// Nil restores the default section headers.
+	parsed, err = configparser.ParseReaderWithOptions(
+		strings.NewReader("[server]\nhost = a\n"),
+		configparser.SectionHeader(nil),
+	)
+	c.Assert(err, IsNil)
+	c.Assert(parsed.Sections(), DeepEquals, []string{"server"})
+	parsed, err = configparser.ParseReaderWithOptions(
+		strings.NewReader("[server]\nhost = a\n"),
+		configparser.SectionMatcher(nil),
+	)
+	c.Assert(err, IsNil)
+	c.Assert(parsed.Sections(), DeepEquals, []string{"server"})
+}
+
+// TestSectionMatcher tests custom section header matcher with quoted
+// subsection names.
+func (s *ConfigParserSuite) TestSectionMatcher(c *C) {
+	parsed, err := configparser.ParseReaderWithOptions(
+		strings.NewReader("[remote \"origin\"]\nurl = git@example.com:app.git\n\n[core]\nbare = false\n"),
+		configparser.SectionMatcher(func(line string) (string, bool) {
+			if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") {
+				return "", false
+			}
+			name, sub, ok := strings.Cut(line[1:len(line)-1], " ")
+			if !ok {
+				return name, true
+			}
+
+			return name + "." + strings.Trim(sub, `"`), true
+		}),
+	)
+	c.Assert(err, IsNil)
+
+	c.Assert(parsed.Sections(), DeepEquals, []string{"core", "remote.origin"})
+}
+
+// TestSectionNameValidator tests section name validation when parsing and
+// adding sections.
+func (s *ConfigParserSuite) TestSectionNameValidator(c *C) {
+	validator := configparser.SectionNameValidator(func(name string) error {
+		if strings.ContainsAny(name, " \t") {
+			return errors.New("whitespace is not allowed")
+		}
+		return nil
+	})
+
+	_, err := configparser.ParseReaderWithOptions(
+		strings.NewReader("[server]\nhost = a\n\n[bad name]\nkey = b\n"), validator,
+	)
+	c.Assert(err, ErrorMatches, `invalid section name "bad name": whitespace is not allowed: 4`)
+
+	// DEFAULT section is not validated.
+	strict := configparser.SectionNameValidator(func(name string) error {
+		if strings.ToLower(name) != name {
+			return errors.New("uppercase is not allowed")
+		}
+		return nil
+	})
+	_, err = configparser.ParseReaderWithOptions(strings.NewReader("[DEFAULT]\nkey = a\n"), strict)
+	c.Assert(err, IsNil)
+
+	p := configparser.NewWithOptions(validator)
+	assertSuccessful(c, p.AddSection("server"))
+	err = p.AddSection("other server")
+	c.Assert(err, ErrorMatches, `invalid section name "other server": whitespace is not allowed`)
+}
@@ -0,0 +1,50 @@
+package configparser
+
+import "fmt"
+
+// Origin describes where the value of an option came from.
+type Origin struct {
+	// Source is the file name or the name of the layer which set the value.
+	Source string
+	// Line is the line number within Source, 0 if unknown.
+	Line int
+}
+
+// String returns the origin in the "source:line" format.
+func (o Origin) String() string {
+	if o.Line == 0 {
+		return o.Source
+	}
+
+	return fmt.Sprintf("%s:%d", o.Source, o.Line)
+}
+
+// origin returns the recorded origin of the option.
+func (s *Section) origin(key string) (Origin, bool) {
+	o, present := s.origins[s.safeKey(key)]
+
+	return o, present
+}
+
+// Origin returns where the value of the named option came from, falling back
+// to the defaults the same way as Get does.
+//
+// Returns zero Origin if the option was set without a known origin.
+// Returns an error if a section does not exist.
+// Returns an error if the option does not exist either in the section or in
+// the defaults.
+func (p *ConfigParser) Origin(section, option string) (Origin, error) {
+	chain, err := p.chain(section)
+	if err != nil {
+		return Origin{}, err
+	}
+
+	for _, s := range chain {
+		if _, err := s.Get(option); err == nil {
+			o, _ := s.origin(option)
+			return o, nil
+		}
+	}
+
+	return Origin{}, getNoOptionError(section, option)
+}
@@ -0,0 +1,40 @@
+package configparser_test
+
+import (
+	"github.com/bigkevmcd/go-configparser"
+
+	gc "gopkg.in/check.v1"
+)
+
+// Origin(section, option) should return the file and line the option was
+// parsed from.
+func (s *ConfigParserSuite) TestOrigin(c *gc.C) {
+	origin, err := s.p.Origin("follower", "builder_command")
+	c.Assert(err, gc.IsNil)
+	c.Assert(origin, gc.Equals, configparser.Origin{Source: "testdata/example.cfg", Line: 14})
+	c.Assert(origin.String(), gc.Equals, "testdata/example.cfg:14")
+}
+
+// Origin(section, option) should fallback to the defaults.
+func (s *ConfigParserSuite) TestOriginFromDefaults(c *gc.C) {
+	origin, err := s.p.Origin("follower", "base_dir")
+	c.Assert(err, gc.IsNil)
+	c.Assert(origin, gc.Equals, configparser.Origin{Source: "testdata/example.cfg", Line: 8})
+}
+
+// Origin(section, option) should be dropped when the option is set.
+func (s *ConfigParserSuite) TestOriginAfterSet(c *gc.C) {
+	assertSuccessful(c, s.p.Set("follower", "builder_command", "make"))
+
+	origin, err := s.p.Origin("follower", "builder_command")
+	c.Assert(err, gc.IsNil)
+	c.Assert(origin, gc.Equals, configparser.Origin{})
+}
+
+// Origin(section, option) should return an error for missing options.
+func (s *ConfigParserSuite) TestOriginMissingOption(c *gc.C) {
+	_, err := s.p.Origin("follower", "missing")
+	c.Assert(err, gc.ErrorMatches, "no option \"missing\" in section: \"follower\"")
+	_, err = s.p.Origin("missing", "missing")
+	c.Assert(err, gc.ErrorMatches, "no section: \"missing\"")
+}
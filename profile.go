@@ -0,0 +1,66 @@
+package configparser
+
+import "strings"
+
+// profileSeparator separates the section and the profile names
+// of the overlay sections.
+const profileSeparator = ":"
+
+// WithProfile returns a view of the configuration with the profile active,
+// see Profile. The view shares the storage and the change subscriptions
+// with p, so it reflects all later changes of p and the subscribers of p are
+// notified of the changes made through the view. Set and RemoveOption change
+// the overlay of the active profile, if the section has one.
+func (p *ConfigParser) WithProfile(name string) *ConfigParser {
+	opt := *p.opt
+	opt.profile = name
+
+	return &ConfigParser{storage: p.storage, opt: &opt}
+}
+
+// ActiveProfile returns the name of the active profile or empty string.
+func (p *ConfigParser) ActiveProfile() string {
+	return p.opt.profile
+}
+
+// overlay returns the overlay section of the active profile for the named
+// section or nil if there is none.
+func (p *ConfigParser) overlay(section string) *Section {
+	if p.opt.profile == "" {
+		return nil
+	}
+
+	return p.config[p.sectionKey(section+profileSeparator+p.opt.profile)]
+}
+
+// isOverlay returns true if the section is the overlay section of any
+// profile for an existing section.
+func (p *ConfigParser) isOverlay(section string) bool {
+	i := strings.LastIndex(section, profileSeparator)
+	if i <= 0 || i == len(section)-len(profileSeparator) {
+		return false
+	}
+	_, err := p.section(section[:i])
+
+	return err == nil
+}
+
+// overlayItems returns a copy of the options of the section with the options
+// of its overlay taking precedence, if a profile is active.
+func (p *ConfigParser) overlayItems(section string, s *Section) Dict {
+	items := s.Items()
+	overlay := p.overlay(section)
+	if overlay == nil {
+		return items
+	}
+	for k := range items {
+		if _, err := overlay.Get(k); err == nil {
+			delete(items, k)
+		}
+	}
+	for k, v := range overlay.options {
+		items[k] = v
+	}
+
+	return items
+}
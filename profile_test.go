@@ -0,0 +1,233 @@
+package configparser_test
+
+import (
+	"strings"
+
+	"github.com/bigkevmcd/go-configparser"
+
+	gc "gopkg.in/check.v1"
+)
+
+const profileConfig = `[DEFAULT]
+debug = true
+
+[DEFAULT:production]
+debug = false
+
+[database]
+host = localhost
+port = 5432
+url = postgres://%(host)s:%(port)s
+
+[database:production]
+host = db.example.com
+
+[database:staging]
+host = staging.example.com
+`
+
+// WithProfile(name) should return a view with the overlay sections taking
+// precedence and hidden from Sections().
+func (s *ConfigParserSuite) TestWithProfile(c *gc.C) {
+	p := mustParse(c, profileConfig)
+	prod := p.WithProfile("production")
+	c.Assert(prod.ActiveProfile(), gc.Equals, "production")
+
+	v, err := prod.Get("database", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "db.example.com")
+	v, err = prod.Get("database", "port")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "5432")
+	v, err = prod.GetInterpolated("database", "url")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "postgres://db.example.com:5432")
+	b, err := prod.GetBool("database", "debug")
+	c.Assert(err, gc.IsNil)
+	c.Assert(b, gc.Equals, false)
+	c.Assert(prod.Sections(), gc.DeepEquals, []string{"database"})
+	origin, err := prod.Origin("database", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(origin.Line, gc.Equals, 13)
+
+	// Original parser is not affected.
+	v, err = p.Get("database", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "localhost")
+	c.Assert(p.Sections(), gc.DeepEquals, []string{"database"})
+
+	// Storage is shared.
+	assertSuccessful(c, p.Set("database:production", "port", "6432"))
+	v, err = prod.Get("database", "port")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "6432")
+	// Also after the storage is replaced by a transaction.
+	c.Assert(p.Update(func(tx *configparser.Tx) error {
+		return tx.Set("database:production", "port", "7432")
+	}), gc.IsNil)
+	v, err = prod.Get("database", "port")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "7432")
+}
+
+// Set through a profile view should write to the overlay of the active
+// profile, which is looked up first.
+func (s *ConfigParserSuite) TestWithProfileSet(c *gc.C) {
+	p := mustParse(c, profileConfig)
+	prod := p.WithProfile("production")
+
+	assertSuccessful(c, prod.Set("database", "host", "db2.example.com"))
+	v, err := prod.Get("database", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "db2.example.com")
+	v, err = p.Get("database", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "localhost")
+
+	// Sections without overlay are changed directly.
+	assertSuccessful(c, p.WithProfile("other").Set("database", "port", "6432"))
+	v, err = prod.Get("database", "port")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "6432")
+}
+
+// Set, RemoveOption and HasOption through a profile view should use the
+// overlay of the active profile first.
+func (s *ConfigParserSuite) TestWithProfileRemoveOption(c *gc.C) {
+	p := mustParse(c, "[db]\nhost = a\n\n[db:prod]\nhost = b\n")
+	v := p.WithProfile("prod")
+
+	assertSuccessful(c, v.Set("db", "user", "admin"))
+	ok, err := v.HasOption("db", "user")
+	c.Assert(err, gc.IsNil)
+	c.Assert(ok, gc.Equals, true)
+	ok, err = p.HasOption("db", "user")
+	c.Assert(err, gc.IsNil)
+	c.Assert(ok, gc.Equals, false)
+
+	assertSuccessful(c, v.RemoveOption("db", "host"))
+	value, err := v.Get("db", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(value, gc.Equals, "a")
+	value, err = p.Get("db", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(value, gc.Equals, "a")
+
+	// Options missing from the overlay are removed from the section.
+	assertSuccessful(c, v.RemoveOption("db", "host"))
+	ok, err = v.HasOption("db", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(ok, gc.Equals, false)
+}
+
+// Changes made through a profile view should notify the subscribers of the
+// original parser.
+func (s *ConfigParserSuite) TestWithProfileNotifies(c *gc.C) {
+	p := mustParse(c, "[db]\nhost = a\n\n[db:prod]\nhost = b\n")
+	var changes []configparser.Change
+	p.Subscribe(func(ch configparser.Change) {
+		changes = append(changes, ch)
+	})
+	v := p.WithProfile("prod")
+
+	assertSuccessful(c, v.Set("db", "host", "c"))
+	c.Assert(v.Update(func(tx *configparser.Tx) error {
+		return tx.Set("db", "port", "5432")
+	}), gc.IsNil)
+	assertSuccessful(c, p.Set("db", "host", "d"))
+
+	c.Assert(changes, gc.DeepEquals, []configparser.Change{
+		{Kind: configparser.OptionChanged, Section: "db:prod", Option: "host", OldValue: "b", NewValue: "c"},
+		{Kind: configparser.OptionAdded, Section: "db:prod", Option: "port", NewValue: "5432"},
+		{Kind: configparser.OptionChanged, Section: "db", Option: "host", OldValue: "a", NewValue: "d"},
+	})
+}
+
+// Items through a profile view should apply the overlay, also to DEFAULT
+// of the section proxies.
+func (s *ConfigParserSuite) TestWithProfileItems(c *gc.C) {
+	prod := mustParse(c, profileConfig).WithProfile("production")
+
+	items, err := prod.Items("database")
+	c.Assert(err, gc.IsNil)
+	c.Assert(items, gc.DeepEquals, configparser.Dict{
+		"host": "db.example.com",
+		"port": "5432",
+		"url":  "postgres://%(host)s:%(port)s",
+	})
+	items, err = prod.Items("DEFAULT")
+	c.Assert(err, gc.IsNil)
+	c.Assert(items, gc.DeepEquals, configparser.Dict{"debug": "false"})
+
+	proxy, err := prod.Section("DEFAULT")
+	c.Assert(err, gc.IsNil)
+	c.Assert(proxy.Keys(), gc.DeepEquals, []string{"debug"})
+	items, err = proxy.Items()
+	c.Assert(err, gc.IsNil)
+	c.Assert(items, gc.DeepEquals, configparser.Dict{"debug": "false"})
+}
+
+// The extends key of a section should be found with an overlay missing it.
+func (s *ConfigParserSuite) TestWithProfileExtends(c *gc.C) {
+	p, err := configparser.ParseReaderWithOptions(
+		strings.NewReader("[base]\nport = 1\n\n[db]\n__extends__ = base\n\n[db:prod]\nhost = b\n"),
+		configparser.Inheritance,
+	)
+	c.Assert(err, gc.IsNil)
+
+	v, err := p.WithProfile("prod").Get("db", "__extends__")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "base")
+}
+
+// Merge into a profile view should write the merged values only to
+// the overlay, like Set.
+func (s *ConfigParserSuite) TestWithProfileMerge(c *gc.C) {
+	p := mustParse(c, "[db]\nhost = a\n\n[db:prod]\nport = 1\n")
+
+	err := p.WithProfile("prod").Merge(mustParse(c, "[db]\nhost = b\n"), configparser.MergeOverride)
+	c.Assert(err, gc.IsNil)
+	items, err := p.Items("db")
+	c.Assert(err, gc.IsNil)
+	c.Assert(items, gc.DeepEquals, configparser.Dict{"host": "a"})
+	items, err = p.Items("db:prod")
+	c.Assert(err, gc.IsNil)
+	c.Assert(items, gc.DeepEquals, configparser.Dict{"host": "b", "port": "1"})
+}
+
+// Sections with the separator should be overlays only if they name
+// an existing section.
+func (s *ConfigParserSuite) TestProfileOverlayNames(c *gc.C) {
+	p := mustParse(c, "[host:8080]\nname = web\n\n[db]\n\n[db:8080]\nname = db\n")
+	prod := p.WithProfile("8080")
+
+	c.Assert(prod.Sections(), gc.DeepEquals, []string{"db", "host:8080"})
+	v, err := prod.Get("host:8080", "name")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "web")
+	v, err = prod.Get("db", "name")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "db")
+}
+
+// Profile(name) should activate the profile when parsing.
+func (s *ConfigParserSuite) TestProfileOption(c *gc.C) {
+	p, err := configparser.ParseReaderWithOptions(
+		strings.NewReader(profileConfig), configparser.Profile("staging"),
+	)
+	c.Assert(err, gc.IsNil)
+
+	items, err := p.ItemsWithDefaults("database")
+	c.Assert(err, gc.IsNil)
+	c.Assert(items, gc.DeepEquals, configparser.Dict{
+		"debug": "true",
+		"host":  "staging.example.com",
+		"port":  "5432",
+		"url":   "postgres://%(host)s:%(port)s",
+	})
+
+	// Overlays are written back.
+	var buf strings.Builder
+	c.Assert(p.WriteWithDelimiter(&buf, "="), gc.IsNil)
+	c.Assert(buf.String(), gc.Matches, `(?s).*\[database:staging\]\nhost = staging.example.com\n.*`)
+}
@@ -0,0 +1,160 @@
+package configparser
+
+// SectionProxy provides access to the options of a section, falling back
+// to the defaults. Changes are written through to the ConfigParser.
+type SectionProxy struct {
+	p    *ConfigParser
+	name string
+}
+
+// Section returns a proxy of the named section, including DEFAULT.
+//
+// Returns an error if the section does not exist.
+func (p *ConfigParser) Section(name string) (*SectionProxy, error) {
+	if _, err := p.section(name); err != nil {
+		return nil, err
+	}
+
+	return &SectionProxy{p: p, name: name}, nil
+}
+
+// Name returns the name of the section.
+func (s *SectionProxy) Name() string {
+	return s.name
+}
+
+// Get returns the interpolated string value for the named option.
+//
+// Returns an error if the section does not exist.
+// Returns an error if the option does not exist either in the section or in
+// the defaults.
+func (s *SectionProxy) Get(option string) (string, error) {
+	result, err := s.interpolated(option)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := s.p.opt.converters[StringConv](result)
+	if err != nil {
+		return "", err
+	}
+
+	return assertValue[string](value)
+}
+
+// GetRaw returns the string value for the named option without
+// interpolation, see ConfigParser.Get.
+func (s *SectionProxy) GetRaw(option string) (string, error) {
+	return s.p.Get(s.name, option)
+}
+
+// GetInt64 returns int64 representation of the interpolated value of
+// the named option.
+func (s *SectionProxy) GetInt64(option string) (int64, error) {
+	result, err := s.interpolated(option)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := s.p.opt.converters[IntConv](result)
+	if err != nil {
+		return 0, err
+	}
+
+	return assertValue[int64](value)
+}
+
+// GetFloat64 returns float64 representation of the interpolated value of
+// the named option.
+func (s *SectionProxy) GetFloat64(option string) (float64, error) {
+	result, err := s.interpolated(option)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := s.p.opt.converters[FloatConv](result)
+	if err != nil {
+		return 0, err
+	}
+
+	return assertValue[float64](value)
+}
+
+// GetBool returns bool representation of the interpolated value of
+// the named option.
+func (s *SectionProxy) GetBool(option string) (bool, error) {
+	result, err := s.interpolated(option)
+	if err != nil {
+		return false, err
+	}
+
+	value, err := s.p.opt.converters[BoolConv](result)
+	if err != nil {
+		return false, err
+	}
+
+	return assertValue[bool](value)
+}
+
+// interpolated returns the interpolated value of the named option, with
+// references looked up in the whole lookup chain of the section, including
+// the parent and the inherited sections and the defaults.
+func (s *SectionProxy) interpolated(option string) (string, error) {
+	dicts, err := s.p.interpolationDicts(s.name)
+	if err != nil {
+		return "", err
+	}
+
+	return s.p.getInterpolated(s.name, option, s.p.newInterpolator(dicts...))
+}
+
+// Set puts the given option into the section, see ConfigParser.Set.
+func (s *SectionProxy) Set(option, value string) error {
+	return s.p.Set(s.name, option, value)
+}
+
+// Remove removes the option from the section, see ConfigParser.RemoveOption.
+func (s *SectionProxy) Remove(option string) error {
+	return s.p.RemoveOption(s.name, option)
+}
+
+// Has returns true if the option exists either in the section or in
+// the defaults.
+func (s *SectionProxy) Has(option string) bool {
+	_, err := s.p.get(s.name, option)
+
+	return err == nil
+}
+
+// Keys returns a sorted list of option names, including the defaults.
+//
+// Returns nil if the section no longer exists.
+func (s *SectionProxy) Keys() []string {
+	if s.p.isDefaultSection(s.name) {
+		// Defaults always exist, so there can't be an error.
+		chain, _ := s.p.chain(s.name)
+		return s.p.chainOptions(chain)
+	}
+	keys, _ := s.p.Options(s.name)
+
+	return keys
+}
+
+// Len returns the number of options, including the defaults.
+func (s *SectionProxy) Len() int {
+	return len(s.Keys())
+}
+
+// Items returns a copy of the section Dict including the defaults,
+// see ConfigParser.ItemsWithDefaults.
+func (s *SectionProxy) Items() (Dict, error) {
+	if s.p.isDefaultSection(s.name) {
+		chain, err := s.p.chain(s.name)
+		if err != nil {
+			return nil, err
+		}
+		return s.p.chainItems(chain), nil
+	}
+
+	return s.p.ItemsWithDefaults(s.name)
+}
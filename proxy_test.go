@@ -0,0 +1,108 @@
+package configparser_test
+
+import (
+	"github.com/bigkevmcd/go-configparser"
+
+	gc "gopkg.in/check.v1"
+)
+
+// Section(name) should return an error if the section does not exist.
+func (s *ConfigParserSuite) TestSectionProxyNoSection(c *gc.C) {
+	_, err := s.p.Section("unknown")
+	c.Assert(err, gc.ErrorMatches, "no section: \"unknown\"")
+}
+
+// SectionProxy getters should interpolate the values and fall back to
+// the defaults.
+func (s *ConfigParserSuite) TestSectionProxyGet(c *gc.C) {
+	follower, err := s.p.Section("follower")
+	c.Assert(err, gc.IsNil)
+	c.Assert(follower.Name(), gc.Equals, "follower")
+
+	v, err := follower.Get("log_dir")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "/srv/logs")
+	v, err = follower.GetRaw("log_dir")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "%(base_dir)s/logs")
+	v, err = follower.Get("bin_dir")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "/srv/bin")
+
+	n, err := follower.GetInt64("max_build_time")
+	c.Assert(err, gc.IsNil)
+	c.Assert(n, gc.Equals, int64(200))
+	f, err := follower.GetFloat64("frobtimeout")
+	c.Assert(err, gc.IsNil)
+	c.Assert(f, gc.Equals, 5.0)
+	_, err = follower.GetBool("max_build_time")
+	c.Assert(err, gc.ErrorMatches, "not a boolean: \"200\"")
+	_, err = follower.Get("unknown")
+	c.Assert(err, gc.ErrorMatches, "no option \"unknown\" in section: \"follower\"")
+
+	c.Assert(follower.Has("base_dir"), gc.Equals, true)
+	c.Assert(follower.Has("unknown"), gc.Equals, false)
+	c.Assert(follower.Keys(), gc.DeepEquals, []string{
+		"FrobTimeout", "TableName", "base_dir", "bin_dir", "builder_command", "log_dir", "max_build_time",
+	})
+	c.Assert(follower.Len(), gc.Equals, 7)
+}
+
+// SectionProxy getters should not resolve the interpolations with
+// the options of the sections read before.
+func (s *ConfigParserSuite) TestSectionProxyInterpolationIsolated(c *gc.C) {
+	p := mustParse(c, "[a]\nsecret = hunter2\n\n[b]\nref = %(secret)s\n")
+
+	a, err := p.Section("a")
+	c.Assert(err, gc.IsNil)
+	v, err := a.Get("secret")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "hunter2")
+
+	b, err := p.Section("b")
+	c.Assert(err, gc.IsNil)
+	v, err = b.Get("ref")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "")
+}
+
+// SectionProxy should write the changes through to the ConfigParser.
+func (s *ConfigParserSuite) TestSectionProxySetRemove(c *gc.C) {
+	follower, err := s.p.Section("follower")
+	c.Assert(err, gc.IsNil)
+
+	assertSuccessful(c, follower.Set("enabled", "yes"))
+	b, err := s.p.GetBool("follower", "enabled")
+	c.Assert(err, gc.IsNil)
+	c.Assert(b, gc.Equals, true)
+
+	assertSuccessful(c, follower.Remove("max_build_time"))
+	c.Assert(follower.Has("max_build_time"), gc.Equals, false)
+	err = follower.Remove("max_build_time")
+	c.Assert(err, gc.ErrorMatches, "no option \"max_build_time\" in section: \"follower\"")
+
+	defaults, err := s.p.Section("DEFAULT")
+	c.Assert(err, gc.IsNil)
+	assertSuccessful(c, defaults.Set("base_dir", "/opt"))
+	v, err := follower.Get("log_dir")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "/opt/logs")
+	c.Assert(defaults.Keys(), gc.DeepEquals, []string{"base_dir", "bin_dir"})
+
+	assertSuccessful(c, s.p.RemoveSection("follower"))
+	_, err = follower.Get("log_dir")
+	c.Assert(err, gc.ErrorMatches, "no section: \"follower\"")
+	c.Assert(follower.Len(), gc.Equals, 0)
+}
+
+// Section.Items() should return a copy of the options.
+func (s *ConfigParserSuite) TestSectionItemsCopy(c *gc.C) {
+	items, err := s.p.Items("whitespace")
+	c.Assert(err, gc.IsNil)
+	items["foo"] = "changed"
+
+	v, err := s.p.Get("whitespace", "foo")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "bar")
+	c.Assert(items, gc.DeepEquals, configparser.Dict{"foo": "changed"})
+}
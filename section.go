@@ -7,17 +7,101 @@ type Section struct {
 	Name    string
 	options Dict
 	lookup  Dict
+	origins map[string]Origin
+	// values contains all values of the options collected from the repeated
+	// keys, see DuplicateCollect.
+	values map[string][]string
+	// comments contains the inline comments of the values of the options
+	// in the order of getAll.
+	comments map[string][]string
+	// transform returns the lookup key of an option name, see
+	// OptionTransform. Option names are case-insensitive if nil.
+	transform func(string) string
 }
 
 // Add adds new key-value pair to the section.
 func (s *Section) Add(key, value string) error {
+	return s.addWithOrigin(key, value, Origin{})
+}
+
+// addWithOrigin adds new key-value pair to the section recording where
+// the value came from. Zero Origin drops the previously recorded one.
+// Inline comments of the replaced values are dropped.
+func (s *Section) addWithOrigin(key, value string, origin Origin) error {
 	lookupKey := s.safeKey(key)
+	// Equivalent names keep the spelling of the existing option.
+	if existing, present := s.lookup[lookupKey]; present {
+		key = existing
+	}
 	s.options[key] = s.safeValue(value)
 	s.lookup[lookupKey] = key
+	delete(s.values, lookupKey)
+	delete(s.comments, lookupKey)
+	s.setOrigin(lookupKey, origin)
 
 	return nil
 }
 
+// appendWithOrigin adds another value of the option, which is returned by
+// Get, while all values are returned by getAll.
+func (s *Section) appendWithOrigin(key, value string, origin Origin) {
+	lookupKey := s.safeKey(key)
+	existing, present := s.lookup[lookupKey]
+	if !present {
+		// Add never returns an error.
+		_ = s.addWithOrigin(key, value, origin)
+		return
+	}
+
+	values, collected := s.values[lookupKey]
+	if !collected {
+		values = []string{s.options[existing]}
+	}
+	value = s.safeValue(value)
+	s.values[lookupKey] = append(values, value)
+	s.options[existing] = value
+	s.setOrigin(lookupKey, origin)
+}
+
+// setComment records the inline comment of the i-th value of the option,
+// see getAll. Empty comment drops the previously recorded one.
+func (s *Section) setComment(key string, i int, comment string) {
+	lookupKey := s.safeKey(key)
+	comments := s.comments[lookupKey]
+	for len(comments) <= i {
+		comments = append(comments, "")
+	}
+	comments[i] = comment
+	for len(comments) > 0 && comments[len(comments)-1] == "" {
+		comments = comments[:len(comments)-1]
+	}
+	if len(comments) == 0 {
+		delete(s.comments, lookupKey)
+	} else {
+		s.comments[lookupKey] = comments
+	}
+}
+
+// comment returns the inline comment of the i-th value of the option,
+// see getAll.
+func (s *Section) comment(key string, i int) string {
+	if comments := s.comments[s.safeKey(key)]; i < len(comments) {
+		return comments[i]
+	}
+
+	return ""
+}
+
+// setOrigin records the origin of the option, zero Origin drops
+// the previously recorded one.
+func (s *Section) setOrigin(lookupKey string, origin Origin) {
+	if origin == (Origin{}) {
+		delete(s.origins, lookupKey)
+	} else {
+		s.origins[lookupKey] = origin
+	}
+}
+
 // Get returns value of an option with the given key.
 //
 // Returns an error if the option does not exist either in the section or in
@@ -34,14 +118,50 @@ func (s *Section) Get(key string) (string, error) {
 	return "", getNoOptionError(s.Name, key)
 }
 
+// getAll returns all values of the option in order.
+func (s *Section) getAll(key string) ([]string, bool) {
+	lookupKey := s.safeKey(key)
+	if values, collected := s.values[lookupKey]; collected {
+		return append([]string(nil), values...), true
+	}
+	value, err := s.Get(key)
+	if err != nil {
+		return nil, false
+	}
+
+	return []string{value}, true
+}
+
+// joinedValue returns all values of the option joined with newlines like
+// the lines of a multiline value, see getAll.
+func (s *Section) joinedValue(key string) (string, bool) {
+	values, present := s.getAll(key)
+
+	return strings.Join(values, "\n"), present
+}
+
+// setAllWithOrigin replaces the values of the option, see getAll.
+func (s *Section) setAllWithOrigin(key string, values []string, origin Origin) {
+	// Add never returns an error.
+	_ = s.addWithOrigin(key, values[0], origin)
+	for _, value := range values[1:] {
+		s.appendWithOrigin(key, value, origin)
+	}
+}
+
 // Options returns a slice of option names.
 func (s *Section) Options() []string {
 	return s.options.Keys()
 }
 
-// Items returns a Dict with the key-value pairs.
+// Items returns a copy of the Dict with the key-value pairs.
 func (s *Section) Items() Dict {
-	return s.options
+	items := make(Dict, len(s.options))
+	for k, v := range s.options {
+		items[k] = v
+	}
+
+	return items
 }
 
 func (s *Section) safeValue(in string) string {
@@ -49,7 +169,12 @@ func (s *Section) safeValue(in string) string {
 }
 
 func (s *Section) safeKey(in string) string {
-	return strings.ToLower(strings.TrimSpace(in))
+	in = strings.TrimSpace(in)
+	if s.transform == nil {
+		return strings.ToLower(in)
+	}
+
+	return s.transform(in)
 }
 
 // Remove removes option with the given name from the section.
@@ -57,23 +182,51 @@ func (s *Section) safeKey(in string) string {
 // Returns an error if the option does not exist either in the section or in
 // the defaults.
 func (s *Section) Remove(key string) error {
-	_, present := s.options[key]
+	lookupKey := s.safeKey(key)
+	option, present := s.lookup[lookupKey]
 	if !present {
 		return getNoOptionError(s.Name, key)
 	}
 
-	// delete doesn't return anything, but this does require
-	// that the passed key to be removed matches the options key.
-	delete(s.lookup, s.safeKey(key))
-	delete(s.options, key)
+	delete(s.lookup, lookupKey)
+	delete(s.origins, lookupKey)
+	delete(s.values, lookupKey)
+	delete(s.comments, lookupKey)
+	delete(s.options, option)
 
 	return nil
 }
 
+// clone returns a deep copy of the section.
+func (s *Section) clone() *Section {
+	c := newSection(s.Name)
+	c.transform = s.transform
+	for k, v := range s.options {
+		c.options[k] = v
+	}
+	for k, v := range s.lookup {
+		c.lookup[k] = v
+	}
+	for k, v := range s.origins {
+		c.origins[k] = v
+	}
+	for k, v := range s.values {
+		c.values[k] = append([]string(nil), v...)
+	}
+	for k, v := range s.comments {
+		c.comments[k] = append([]string(nil), v...)
+	}
+
+	return c
+}
+
 func newSection(name string) *Section {
 	return &Section{
-		Name:    name,
-		options: make(Dict),
-		lookup:  make(Dict),
+		Name:     name,
+		options:  make(Dict),
+		lookup:   make(Dict),
+		origins:  make(map[string]Origin),
+		values:   make(map[string][]string),
+		comments: make(map[string][]string),
 	}
 }
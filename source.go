@@ -0,0 +1,202 @@
+package configparser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Source is a layer of configuration, which can be loaded into
+// a ConfigParser, see Load.
+type Source interface {
+	// Name identifies the source in the recorded origins of the options.
+	Name() string
+	// Load loads the configuration into the ConfigParser.
+	Load(p *ConfigParser) error
+}
+
+// Load creates a new ConfigParser from the sources, see ConfigParser.Load.
+func Load(sources ...Source) (*ConfigParser, error) {
+	p := New()
+	if err := p.Load(sources...); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Load loads the sources in the given order, so the options of later sources
+// take precedence. Loaded options record the name of the source as their
+// origin, unless a more precise one is known, e.g. the parsed file.
+func (p *ConfigParser) Load(sources ...Source) error {
+	for _, src := range sources {
+		p.source = src.Name()
+		err := src.Load(p)
+		p.source = ""
+		if err != nil {
+			return fmt.Errorf("%s: %w", src.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// ensureSection adds the section if it does not exist.
+func (p *ConfigParser) ensureSection(section string) error {
+	if p.isDefaultSection(section) || p.HasSection(section) {
+		return nil
+	}
+
+	return p.AddSection(section)
+}
+
+type fileSource struct {
+	filename string
+}
+
+// FileSource returns a Source which parses the named file, using the file
+// system of the ConfigParser.
+func FileSource(filename string) Source {
+	return fileSource{filename: filename}
+}
+
+func (s fileSource) Name() string { return s.filename }
+
+func (s fileSource) Load(p *ConfigParser) error {
+	return p.parseFile(s.filename, parseState{})
+}
+
+type readerSource struct {
+	name string
+	in   io.Reader
+}
+
+// ReaderSource returns a Source which parses the data from the reader.
+func ReaderSource(name string, in io.Reader) Source {
+	return readerSource{name: name, in: in}
+}
+
+func (s readerSource) Name() string { return s.name }
+
+func (s readerSource) Load(p *ConfigParser) error {
+	return p.ParseReader(s.in)
+}
+
+type bytesSource struct {
+	name string
+	data []byte
+}
+
+// BytesSource returns a Source which parses the data.
+func BytesSource(name string, data []byte) Source {
+	return bytesSource{name: name, data: data}
+}
+
+func (s bytesSource) Name() string { return s.name }
+
+func (s bytesSource) Load(p *ConfigParser) error {
+	return p.ParseReader(bytes.NewReader(s.data))
+}
+
+type mapSource struct {
+	name     string
+	sections map[string]Dict
+}
+
+// MapSource returns a Source which sets the options of the sections,
+// missing sections are added.
+func MapSource(name string, sections map[string]Dict) Source {
+	return mapSource{name: name, sections: sections}
+}
+
+func (s mapSource) Name() string { return s.name }
+
+func (s mapSource) Load(p *ConfigParser) error {
+	sections := make([]string, 0, len(s.sections))
+	for section := range s.sections {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+
+	for _, section := range sections {
+		options := s.sections[section]
+		if err := p.ensureSection(section); err != nil {
+			return err
+		}
+		for _, option := range options.Keys() {
+			if err := p.Set(section, option, options[option]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// overridesSourceName is the name of the Source returned by OverrideSource.
+const overridesSourceName = "overrides"
+
+type overrideSource struct {
+	overrides []string
+}
+
+// OverrideSource returns a Source which applies command-line like overrides
+// in the "section.option=value" format, missing sections are added.
+func OverrideSource(overrides ...string) Source {
+	return overrideSource{overrides: overrides}
+}
+
+func (s overrideSource) Name() string { return overridesSourceName }
+
+func (s overrideSource) Load(p *ConfigParser) error {
+	for _, o := range s.overrides {
+		section, option, value, err := parseOverride(o)
+		if err != nil {
+			return err
+		}
+		if err := p.ensureSection(section); err != nil {
+			return err
+		}
+		if err := p.Set(section, option, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseOverride parses the "section.option=value" override, the section
+// is separated by the last dot, so it may contain dots itself.
+func parseOverride(o string) (section, option, value string, err error) {
+	key, value, ok := strings.Cut(o, "=")
+	if ok {
+		dot := strings.LastIndex(key, ".")
+		if dot > 0 && dot < len(key)-1 {
+			return strings.TrimSpace(key[:dot]), strings.TrimSpace(key[dot+1:]), value, nil
+		}
+	}
+
+	return "", "", "", fmt.Errorf("invalid override %q: expected section.option=value", o)
+}
+
+// envSourceName is the name of the Source returned by EnvSource.
+const envSourceName = "env"
+
+type envSource struct {
+	prefix string
+	opts   []envOptFunc
+}
+
+// EnvSource returns a Source which sets the options from the environment
+// variables with the prefix, see ApplyEnv.
+func EnvSource(prefix string, opts ...envOptFunc) Source {
+	return envSource{prefix: prefix, opts: opts}
+}
+
+func (s envSource) Name() string { return envSourceName }
+
+func (s envSource) Load(p *ConfigParser) error {
+	return p.ApplyEnv(s.prefix, s.opts...)
+}
@@ -0,0 +1,66 @@
+package configparser_test
+
+import (
+	"os"
+	"strings"
+
+	"github.com/bigkevmcd/go-configparser"
+
+	gc "gopkg.in/check.v1"
+)
+
+// Load(sources...) should apply the sources in order and record their
+// names as origins of the options.
+func (s *ConfigParserSuite) TestLoad(c *gc.C) {
+	c.Assert(os.Setenv("TESTAPP_DB__USER", "admin"), gc.IsNil)
+	defer os.Unsetenv("TESTAPP_DB__USER")
+
+	p, err := configparser.Load(
+		configparser.FileSource("testdata/example.cfg"),
+		configparser.BytesSource("defaults", []byte("[db]\nhost = localhost\nport = 5432\n")),
+		configparser.ReaderSource("site", strings.NewReader("[db]\nhost = db.example.com\n")),
+		configparser.MapSource("builtin", map[string]configparser.Dict{
+			"cache":   {"size": "10"},
+			"DEFAULT": {"base_dir": "/opt"},
+		}),
+		configparser.EnvSource("TESTAPP_", configparser.EnvCreateSections),
+		configparser.OverrideSource("db.port=5433", "server.eu.region=fra1"),
+	)
+	c.Assert(err, gc.IsNil)
+	c.Assert(p.Sections(), gc.DeepEquals, []string{"cache", "db", "empty", "follower", "server.eu", "whitespace"})
+
+	for _, expected := range []struct {
+		section, option, value string
+		origin                 configparser.Origin
+	}{
+		{"follower", "max_build_time", "200", configparser.Origin{Source: "testdata/example.cfg", Line: 13}},
+		{"db", "host", "db.example.com", configparser.Origin{Source: "site", Line: 2}},
+		{"db", "port", "5433", configparser.Origin{Source: "overrides"}},
+		{"db", "user", "admin", configparser.Origin{Source: "env"}},
+		{"cache", "size", "10", configparser.Origin{Source: "builtin"}},
+		{"follower", "base_dir", "/opt", configparser.Origin{Source: "builtin"}},
+		{"server.eu", "region", "fra1", configparser.Origin{Source: "overrides"}},
+	} {
+		v, err := p.Get(expected.section, expected.option)
+		c.Assert(err, gc.IsNil)
+		c.Assert(v, gc.Equals, expected.value)
+		origin, err := p.Origin(expected.section, expected.option)
+		c.Assert(err, gc.IsNil)
+		c.Assert(origin, gc.Equals, expected.origin)
+	}
+
+	// Options set outside of Load have no origin.
+	assertSuccessful(c, p.Set("db", "host", "localhost"))
+	origin, err := p.Origin("db", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(origin, gc.Equals, configparser.Origin{})
+}
+
+// Load(sources...) should report the name of the failed source.
+func (s *ConfigParserSuite) TestLoadError(c *gc.C) {
+	_, err := configparser.Load(configparser.OverrideSource("db=5433"))
+	c.Assert(err, gc.ErrorMatches, `overrides: invalid override "db=5433": expected section.option=value`)
+
+	_, err = configparser.Load(configparser.FileSource("testdata/missing.cfg"))
+	c.Assert(err, gc.ErrorMatches, "testdata/missing.cfg: open testdata/missing.cfg: no such file or directory")
+}
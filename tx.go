@@ -0,0 +1,112 @@
+package configparser
+
+import "errors"
+
+// ErrInTransaction is returned by the mutations of a ConfigParser made while
+// its transaction is running, see ConfigParser.Update.
+var ErrInTransaction = errors.New("configuration is being changed by a transaction")
+
+// Validator checks the staged configuration before a transaction is
+// committed, see [ConfigParser.Update].
+type Validator func(*ConfigParser) error
+
+// Tx stages mutations of the ConfigParser, which are applied only when
+// the transaction is committed.
+type Tx struct {
+	staged  *ConfigParser
+	changes []Change
+}
+
+// Update runs fn within a transaction.
+//
+// All the mutations made through the Tx are applied to the ConfigParser at
+// once when fn returns nil and all the validators accept the staged
+// configuration, subscribers are then notified with a single change set.
+// Otherwise the mutations are discarded and the error is returned.
+//
+// Mutations of the ConfigParser and its profile views made directly while fn
+// or the validators run, including nested transactions, fail with
+// ErrInTransaction, as they would be lost on commit.
+func (p *ConfigParser) Update(fn func(*Tx) error, validators ...Validator) error {
+	if p.inTx {
+		return ErrInTransaction
+	}
+	p.inTx = true
+	defer func() { p.inTx = false }()
+
+	tx := &Tx{staged: p.clone()}
+	// Subscription without filters can't fail.
+	_, _ = tx.staged.Subscribe(func(c Change) {
+		tx.changes = append(tx.changes, c)
+	})
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	for _, validate := range validators {
+		if err := validate(tx.staged); err != nil {
+			return err
+		}
+	}
+
+	// Storage is replaced in place, so the profile views see the changes,
+	// keeping the subscriptions of p instead of the staged ones.
+	subscriptions := p.subscriptions
+	*p.storage = *tx.staged.storage
+	p.subscriptions = subscriptions
+	// Subscribers may change the configuration again.
+	p.inTx = false
+	p.notify(tx.changes...)
+
+	return nil
+}
+
+// Changes returns the changes staged so far.
+func (tx *Tx) Changes() []Change {
+	return append([]Change(nil), tx.changes...)
+}
+
+// Get returns the staged string value for the named option.
+func (tx *Tx) Get(section, option string) (string, error) {
+	return tx.staged.Get(section, option)
+}
+
+// Sections returns the staged list of section names, excluding [DEFAULT].
+func (tx *Tx) Sections() []string {
+	return tx.staged.Sections()
+}
+
+// HasSection returns true if the named section is staged.
+func (tx *Tx) HasSection(section string) bool {
+	return tx.staged.HasSection(section)
+}
+
+// HasOption checks if the staged section contains option.
+func (tx *Tx) HasOption(section, option string) (bool, error) {
+	return tx.staged.HasOption(section, option)
+}
+
+// Items returns a copy of the staged section Dict not including the Defaults.
+func (tx *Tx) Items(section string) (Dict, error) {
+	return tx.staged.Items(section)
+}
+
+// AddSection stages creation of a new section.
+func (tx *Tx) AddSection(section string) error {
+	return tx.staged.AddSection(section)
+}
+
+// RemoveSection stages removal of the given section.
+func (tx *Tx) RemoveSection(section string) error {
+	return tx.staged.RemoveSection(section)
+}
+
+// Set stages the given option to be put into the named section.
+func (tx *Tx) Set(section, option, value string) error {
+	return tx.staged.Set(section, option, value)
+}
+
+// RemoveOption stages removal of the option from the section.
+func (tx *Tx) RemoveOption(section, option string) error {
+	return tx.staged.RemoveOption(section, option)
+}
@@ -0,0 +1,120 @@
+package configparser_test
+
+import (
+	"errors"
+
+	"github.com/bigkevmcd/go-configparser"
+
+	gc "gopkg.in/check.v1"
+)
+
+// Update(fn) should apply all the staged mutations and notify subscribers
+// with a single change set.
+func (s *ConfigParserSuite) TestUpdate(c *gc.C) {
+	var sets [][]configparser.Change
+	s.p.SubscribeChangeSet(func(changes []configparser.Change) {
+		sets = append(sets, changes)
+	})
+
+	err := s.p.Update(func(tx *configparser.Tx) error {
+		if err := tx.AddSection("db"); err != nil {
+			return err
+		}
+		if err := tx.Set("db", "host", "localhost"); err != nil {
+			return err
+		}
+		// Staged values are visible within the transaction only.
+		v, err := tx.Get("db", "host")
+		c.Assert(err, gc.IsNil)
+		c.Assert(v, gc.Equals, "localhost")
+		c.Assert(s.p.HasSection("db"), gc.Equals, false)
+
+		return tx.RemoveOption("follower", "max_build_time")
+	})
+	c.Assert(err, gc.IsNil)
+
+	v, err := s.p.Get("db", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "localhost")
+	ok, err := s.p.HasOption("follower", "max_build_time")
+	c.Assert(err, gc.IsNil)
+	c.Assert(ok, gc.Equals, false)
+
+	c.Assert(sets, gc.HasLen, 1)
+	c.Assert(sets[0], gc.DeepEquals, []configparser.Change{
+		{Kind: configparser.SectionAdded, Section: "db"},
+		{Kind: configparser.OptionAdded, Section: "db", Option: "host", NewValue: "localhost"},
+		{Kind: configparser.OptionRemoved, Section: "follower", Option: "max_build_time", OldValue: "200"},
+	})
+}
+
+// Update(fn) should discard all the staged mutations if fn fails.
+func (s *ConfigParserSuite) TestUpdateRollback(c *gc.C) {
+	called := false
+	s.p.Subscribe(func(configparser.Change) { called = true })
+
+	err := s.p.Update(func(tx *configparser.Tx) error {
+		if err := tx.Set("follower", "max_build_time", "100"); err != nil {
+			return err
+		}
+		if err := tx.RemoveSection("whitespace"); err != nil {
+			return err
+		}
+
+		return tx.Set("unknown", "option", "value")
+	})
+	c.Assert(err, gc.ErrorMatches, "no section: \"unknown\"")
+	c.Assert(called, gc.Equals, false)
+
+	v, err := s.p.Get("follower", "max_build_time")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "200")
+	c.Assert(s.p.HasSection("whitespace"), gc.Equals, true)
+}
+
+// Update(fn, validators...) should discard the mutations rejected by a validator.
+func (s *ConfigParserSuite) TestUpdateValidation(c *gc.C) {
+	validator := func(p *configparser.ConfigParser) error {
+		if _, err := p.GetInt64("follower", "max_build_time"); err != nil {
+			return errors.New("max_build_time must be an integer")
+		}
+		return nil
+	}
+
+	err := s.p.Update(func(tx *configparser.Tx) error {
+		return tx.Set("follower", "max_build_time", "forever")
+	}, validator)
+	c.Assert(err, gc.ErrorMatches, "max_build_time must be an integer")
+
+	v, err := s.p.Get("follower", "max_build_time")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "200")
+
+	err = s.p.Update(func(tx *configparser.Tx) error {
+		return tx.Set("follower", "max_build_time", "300")
+	}, validator)
+	c.Assert(err, gc.IsNil)
+
+	v, err = s.p.Get("follower", "max_build_time")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "300")
+}
+
+// Update(fn) should reject the direct mutations made while fn runs, which
+// would be lost on commit, and allow them again afterwards.
+func (s *ConfigParserSuite) TestUpdateDirectMutation(c *gc.C) {
+	err := s.p.Update(func(tx *configparser.Tx) error {
+		c.Assert(s.p.Set("follower", "max_build_time", "100"), gc.Equals, configparser.ErrInTransaction)
+		c.Assert(s.p.AddSection("db"), gc.Equals, configparser.ErrInTransaction)
+		c.Assert(s.p.WithProfile("prod").RemoveSection("whitespace"), gc.Equals, configparser.ErrInTransaction)
+		c.Assert(s.p.Update(func(*configparser.Tx) error { return nil }), gc.Equals, configparser.ErrInTransaction)
+
+		return tx.Set("follower", "max_build_time", "300")
+	})
+	c.Assert(err, gc.IsNil)
+
+	v, err := s.p.Get("follower", "max_build_time")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "300")
+	assertSuccessful(c, s.p.Set("follower", "max_build_time", "400"))
+}
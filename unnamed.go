@@ -0,0 +1,25 @@
+package configparser
+
+// UnnamedSection is the name of the section containing the options before
+// the first section header, see AllowUnnamedSection.
+const UnnamedSection = ""
+
+// unnamedSection returns the unnamed section, which is added if it does not
+// exist, or nil if it is not allowed.
+func (p *ConfigParser) unnamedSection() *Section {
+	if !p.opt.allowUnnamedSection {
+		return nil
+	}
+	if s, err := p.section(UnnamedSection); err == nil {
+		return s
+	}
+	s := p.newSection(UnnamedSection)
+	p.config[p.sectionKey(UnnamedSection)] = s
+
+	return s
+}
+
+// isUnnamedSection returns true if the section is the unnamed section.
+func (p *ConfigParser) isUnnamedSection(section string) bool {
+	return p.sectionKey(section) == p.sectionKey(UnnamedSection)
+}
@@ -0,0 +1,117 @@
+package configparser_test
+
+import (
+	"strings"
+
+	"github.com/bigkevmcd/go-configparser"
+
+	gc "gopkg.in/check.v1"
+)
+
+const unnamedConfig = `name = app
+version = 1.2
+
+[DEFAULT]
+debug = false
+
+[server]
+host = localhost
+`
+
+// AllowUnnamedSection should store the options before the first section
+// header in the unnamed section and write them back without the header.
+func (s *ConfigParserSuite) TestAllowUnnamedSection(c *gc.C) {
+	p, err := configparser.ParseReaderWithOptions(
+		strings.NewReader(unnamedConfig), configparser.AllowUnnamedSection,
+	)
+	c.Assert(err, gc.IsNil)
+
+	v, err := p.Get(configparser.UnnamedSection, "name")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "app")
+	f, err := p.GetFloat64(configparser.UnnamedSection, "version")
+	c.Assert(err, gc.IsNil)
+	c.Assert(f, gc.Equals, 1.2)
+	b, err := p.GetBool(configparser.UnnamedSection, "debug")
+	c.Assert(err, gc.IsNil)
+	c.Assert(b, gc.Equals, false)
+	c.Assert(p.Sections(), gc.DeepEquals, []string{"server"})
+
+	assertSuccessful(c, p.Set(configparser.UnnamedSection, "name", "other"))
+	var buf strings.Builder
+	c.Assert(p.WriteWithDelimiter(&buf, "="), gc.IsNil)
+	c.Assert(buf.String(), gc.Equals, `name = other
+version = 1.2
+
+[DEFAULT]
+debug = false
+
+[server]
+host = localhost
+
+`)
+}
+
+// ListUnnamedSection should include the unnamed section in Sections().
+func (s *ConfigParserSuite) TestListUnnamedSection(c *gc.C) {
+	p, err := configparser.ParseReaderWithOptions(
+		strings.NewReader(unnamedConfig),
+		configparser.AllowUnnamedSection,
+		configparser.ListUnnamedSection,
+	)
+	c.Assert(err, gc.IsNil)
+
+	c.Assert(p.Sections(), gc.DeepEquals, []string{configparser.UnnamedSection, "server"})
+}
+
+// Options before the first section header should be rejected by default.
+func (s *ConfigParserSuite) TestUnnamedSectionNotAllowed(c *gc.C) {
+	_, err := configparser.ParseReader(strings.NewReader(unnamedConfig))
+	c.Assert(err, gc.ErrorMatches, "missing section header: 1 name = app")
+}
+
+// AddSection should reject the unnamed section unless it is allowed.
+func (s *ConfigParserSuite) TestAddUnnamedSection(c *gc.C) {
+	p := configparser.New()
+	err := p.AddSection(configparser.UnnamedSection)
+	c.Assert(err, gc.ErrorMatches, `invalid section name: ""`)
+	c.Assert(p.HasSection(configparser.UnnamedSection), gc.Equals, false)
+
+	p = configparser.NewWithOptions(configparser.AllowUnnamedSection)
+	assertSuccessful(c, p.AddSection(configparser.UnnamedSection))
+	assertSuccessful(c, p.Set(configparser.UnnamedSection, "name", "app"))
+	v, err := p.Get(configparser.UnnamedSection, "name")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "app")
+}
+
+// Merge3 conflicts should write the unnamed section first without
+// the header.
+func (s *ConfigParserSuite) TestUnnamedSectionConflictMarkers(c *gc.C) {
+	parse := func(data string) *configparser.ConfigParser {
+		p, err := configparser.ParseReaderWithOptions(
+			strings.NewReader(data), configparser.AllowUnnamedSection,
+		)
+		c.Assert(err, gc.IsNil)
+
+		return p
+	}
+	base := parse("name = app\n\n[DEFAULT]\ndebug = no\n")
+	ours := parse("name = ours\n\n[DEFAULT]\ndebug = no\n")
+	theirs := parse("name = theirs\n\n[DEFAULT]\ndebug = no\n")
+
+	r, err := configparser.Merge3(base, ours, theirs)
+	c.Assert(err, gc.IsNil)
+	var buf strings.Builder
+	c.Assert(r.WriteWithConflictMarkers(&buf, "="), gc.IsNil)
+	c.Assert(buf.String(), gc.Equals, `<<<<<<< ours
+name = ours
+=======
+name = theirs
+>>>>>>> theirs
+
+[DEFAULT]
+debug = no
+
+`)
+}
@@ -0,0 +1,106 @@
+package configparser
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Locations are the directories searched for the configuration files of
+// an application, see DefaultLocations.
+type Locations struct {
+	// ConfigHome is the user configuration directory, e.g. ~/.config.
+	ConfigHome string
+	// ConfigDirs are the preference ordered configuration directories,
+	// the most important first, as in $XDG_CONFIG_DIRS.
+	ConfigDirs []string
+	// SystemDir is the system configuration directory, e.g. /etc.
+	SystemDir string
+}
+
+// DefaultLocations returns the locations following the XDG Base Directory
+// Specification: $XDG_CONFIG_HOME or the platform user configuration
+// directory, $XDG_CONFIG_DIRS defaulting to /etc/xdg, and /etc.
+func DefaultLocations() Locations {
+	var l Locations
+	if dir, err := os.UserConfigDir(); err == nil {
+		l.ConfigHome = dir
+	}
+	if runtime.GOOS == "windows" {
+		return l
+	}
+
+	for _, dir := range filepath.SplitList(os.Getenv("XDG_CONFIG_DIRS")) {
+		// Relative paths are invalid and should be ignored.
+		if filepath.IsAbs(dir) {
+			l.ConfigDirs = append(l.ConfigDirs, dir)
+		}
+	}
+	if len(l.ConfigDirs) == 0 {
+		l.ConfigDirs = []string{"/etc/xdg"}
+	}
+	l.SystemDir = "/etc"
+
+	return l
+}
+
+// Candidates returns the paths of the configuration file of the application
+// in precedence order, lowest first: the system directory, the configuration
+// directories in reverse order and the user configuration directory.
+// Empty locations are skipped.
+func (l Locations) Candidates(app, file string) []string {
+	dirs := []string{l.SystemDir}
+	for i := len(l.ConfigDirs) - 1; i >= 0; i-- {
+		dirs = append(dirs, l.ConfigDirs[i])
+	}
+	dirs = append(dirs, l.ConfigHome)
+
+	candidates := make([]string, 0, len(dirs))
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		candidate := filepath.Join(dir, app, file)
+		if seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates
+}
+
+// Load loads the existing candidate files into the ConfigParser as layers,
+// see ConfigParser.Load, and returns the paths of the loaded files.
+// Candidates are resolved against the root of the file system set with
+// FileSystem.
+func (l Locations) Load(p *ConfigParser, app, file string) ([]string, error) {
+	var loaded []string
+	for _, candidate := range l.Candidates(app, file) {
+		candidate = p.opt.fsys.Resolve("", candidate)
+		if _, err := p.opt.fsys.Stat(candidate); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err := p.Load(FileSource(candidate)); err != nil {
+			return loaded, err
+		}
+		loaded = append(loaded, candidate)
+	}
+
+	return loaded, nil
+}
+
+// Discover creates a new ConfigParser from the configuration files of the
+// application found in the DefaultLocations.
+func Discover(app, file string, opts ...optFunc) (*ConfigParser, error) {
+	p := NewWithOptions(opts...)
+	if _, err := DefaultLocations().Load(p, app, file); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
@@ -0,0 +1,73 @@
+package configparser_test
+
+import (
+	"path/filepath"
+	"testing/fstest"
+
+	"github.com/bigkevmcd/go-configparser"
+
+	gc "gopkg.in/check.v1"
+)
+
+// Locations.Candidates(app, file) should return the paths lowest precedence
+// first, skipping empty and duplicate locations.
+func (s *ConfigParserSuite) TestLocationsCandidates(c *gc.C) {
+	l := configparser.Locations{
+		ConfigHome: "/home/user/.config",
+		ConfigDirs: []string{"/etc/xdg/vendor", "/etc/xdg", "/etc/xdg"},
+		SystemDir:  "/etc",
+	}
+
+	c.Assert(l.Candidates("app", "app.cfg"), gc.DeepEquals, []string{
+		filepath.Join("/etc", "app", "app.cfg"),
+		filepath.Join("/etc/xdg", "app", "app.cfg"),
+		filepath.Join("/etc/xdg/vendor", "app", "app.cfg"),
+		filepath.Join("/home/user/.config", "app", "app.cfg"),
+	})
+	c.Assert(configparser.Locations{}.Candidates("app", "app.cfg"), gc.HasLen, 0)
+}
+
+// Locations.Load(p, app, file) should load the existing files as layers.
+func (s *ConfigParserSuite) TestLocationsLoad(c *gc.C) {
+	root := c.MkDir()
+	writeFiles(c, root, map[string]string{
+		"etc/app/app.cfg":          "[db]\nhost = localhost\nport = 5432\n",
+		"home/.config/app/app.cfg": "[db]\nport = 5433\n",
+	})
+	l := configparser.Locations{
+		ConfigHome: filepath.Join(root, "home/.config"),
+		ConfigDirs: []string{filepath.Join(root, "etc/xdg")},
+		SystemDir:  filepath.Join(root, "etc"),
+	}
+
+	p := configparser.New()
+	loaded, err := l.Load(p, "app", "app.cfg")
+	c.Assert(err, gc.IsNil)
+	c.Assert(loaded, gc.DeepEquals, []string{
+		filepath.Join(root, "etc/app/app.cfg"),
+		filepath.Join(root, "home/.config/app/app.cfg"),
+	})
+
+	items, err := p.Items("db")
+	c.Assert(err, gc.IsNil)
+	c.Assert(items, gc.DeepEquals, configparser.Dict{"host": "localhost", "port": "5433"})
+	origin, err := p.Origin("db", "port")
+	c.Assert(err, gc.IsNil)
+	c.Assert(origin, gc.Equals, configparser.Origin{Source: filepath.Join(root, "home/.config/app/app.cfg"), Line: 2})
+}
+
+// Locations.Load(p, app, file) should resolve the candidates against the root
+// of the file system.
+func (s *ConfigParserSuite) TestLocationsLoadFS(c *gc.C) {
+	fsys := fstest.MapFS{
+		"etc/app/app.cfg": {Data: []byte("[db]\nhost = localhost\n")},
+	}
+	p := configparser.NewWithOptions(configparser.FileSystem(fsys))
+
+	loaded, err := configparser.Locations{SystemDir: "/etc"}.Load(p, "app", "app.cfg")
+	c.Assert(err, gc.IsNil)
+	c.Assert(loaded, gc.DeepEquals, []string{"etc/app/app.cfg"})
+	v, err := p.Get("db", "host")
+	c.Assert(err, gc.IsNil)
+	c.Assert(v, gc.Equals, "localhost")
+}